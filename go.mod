@@ -5,8 +5,8 @@ require (
 	github.com/MakeNowJust/heredoc v0.0.0-20171113091838-e9091a26100e // indirect
 	github.com/Microsoft/go-winio v0.4.11 // indirect
 	github.com/apparentlymart/go-cidr v1.0.0 // indirect
-	github.com/aws/aws-sdk-go v1.16.11 // indirect
-	github.com/blang/semver v3.5.1+incompatible // indirect
+	github.com/aws/aws-sdk-go v1.16.11
+	github.com/blang/semver v3.5.1+incompatible
 	github.com/boltdb/bolt v1.3.1 // indirect
 	github.com/cockroachdb/cmux v0.0.0-20170110192607-30d10be49292 // indirect
 	github.com/coreos/bbolt v1.3.0 // indirect
@@ -48,6 +48,8 @@ require (
 	github.com/hashicorp/logutils v1.0.0 // indirect
 	github.com/hashicorp/terraform v0.11.11
 	github.com/hashicorp/yamux v0.0.0-20180917205041-7221087c3d28 // indirect
+	github.com/howeyc/gopass v0.0.0-20170109162249-bf9dde6d0d2c // indirect
+	github.com/imdario/mergo v0.0.0-20141206190957-6633656539c1 // indirect
 	github.com/jonboulle/clockwork v0.1.0 // indirect
 	github.com/json-iterator/go v1.1.5 // indirect
 	github.com/jtolds/gls v4.2.1+incompatible // indirect
@@ -76,7 +78,7 @@ require (
 	github.com/vmware/govmomi v0.19.0 // indirect
 	github.com/xiang90/probing v0.0.0-20160813154853-07dd2e8dfe18 // indirect
 	github.com/zclconf/go-cty v0.0.0-20181218225846-4fe1e489ee06 // indirect
-	golang.org/x/crypto v0.0.0-20181203042331-505ab145d0a9 // indirect
+	golang.org/x/crypto v0.0.0-20181203042331-505ab145d0a9
 	golang.org/x/time v0.0.0-20181108054448-85acf8d2951c // indirect
 	google.golang.org/api v0.0.0-20181221000618-65a46cafb132 // indirect
 	google.golang.org/grpc v1.15.0 // indirect
@@ -86,11 +88,11 @@ require (
 	gopkg.in/square/go-jose.v2 v2.1.8 // indirect
 	gopkg.in/warnings.v0 v0.1.2 // indirect
 	gotest.tools v2.2.0+incompatible // indirect
-	k8s.io/api v0.0.0-20180308224125-73d903622b73 // indirect; git tag "kubernetes-1.10.1"
+	k8s.io/api v0.0.0-20180308224125-73d903622b73 // git tag "kubernetes-1.10.1"
 	k8s.io/apiextensions-apiserver v0.0.0-20180412193505-4347b330d0ff // indirect
 	k8s.io/apimachinery v0.0.0-20180228050457-302974c03f7e // git tag "kubernetes-1.10.1"
 	k8s.io/apiserver v0.0.0-20180412185015-06e4be4fafa2 // indirect
-	k8s.io/client-go v7.0.0+incompatible // indirect
+	k8s.io/client-go v7.0.0+incompatible
 	k8s.io/klog v0.1.0 // indirect
 	k8s.io/kops v1.10.0
 	k8s.io/kube-openapi v0.0.0-20180731170545-e3762e86a74c // indirect
@@ -0,0 +1,135 @@
+package kops
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+// auditLog appends a JSON record of every operation performed against the state store to a file
+type auditLog struct {
+	path     string
+	identity string
+	mutex    sync.Mutex
+}
+
+type auditRecord struct {
+	Time       time.Time `json:"time"`
+	Operation  string    `json:"operation"`
+	Kind       string    `json:"kind"`
+	Name       string    `json:"name"`
+	StateStore string    `json:"state_store"`
+	Changes    []string  `json:"changes,omitempty"`
+	Identity   string    `json:"identity"`
+}
+
+func newAuditLog(path string, creds *credentials.Credentials) *auditLog {
+	if path == "" {
+		return nil
+	}
+	return &auditLog{
+		path:     path,
+		identity: callerIdentity(creds),
+	}
+}
+
+func (a *auditLog) write(record auditRecord) error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(a.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("error opening audit log %q: %v", a.path, err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("error writing audit log %q: %v", a.path, err)
+	}
+	return nil
+}
+
+// audit records an operation performed on a resource, it is a no-op unless the provider has audit_log set
+func audit(d *schema.ResourceData, m interface{}, operation, kind, name string) error {
+	config := m.(*ProviderConfig)
+	if config.auditLog == nil {
+		return nil
+	}
+
+	stateStore, _ := d.Get("state_store").(string)
+	if stateStore == "" {
+		stateStore = config.stateStore
+	}
+
+	var changes []string
+	if operation != "delete" {
+		changes = changedAttributes(d, "metadata", "spec")
+	}
+
+	return config.auditLog.write(auditRecord{
+		Time:       time.Now().UTC(),
+		Operation:  operation,
+		Kind:       kind,
+		Name:       name,
+		StateStore: stateStore,
+		Changes:    changes,
+		Identity:   config.auditLog.identity,
+	})
+}
+
+// changedAttributes summarizes a diff as the changed attributes of the given single item blocks
+func changedAttributes(d *schema.ResourceData, keys ...string) []string {
+	var changes []string
+	for _, key := range keys {
+		o, n := d.GetChange(key)
+		before, after := firstItem(o), firstItem(n)
+		for attr := range after {
+			if !reflect.DeepEqual(before[attr], after[attr]) {
+				changes = append(changes, key+"."+attr)
+			}
+		}
+		for attr := range before {
+			if _, ok := after[attr]; !ok {
+				changes = append(changes, key+"."+attr)
+			}
+		}
+	}
+	sort.Strings(changes)
+	return changes
+}
+
+func firstItem(data interface{}) map[string]interface{} {
+	if list, ok := data.([]interface{}); ok && len(list) > 0 {
+		if item, ok := list[0].(map[string]interface{}); ok {
+			return item
+		}
+	}
+	return map[string]interface{}{}
+}
+
+// callerIdentity describes who performs the operations, the AWS identity is preferred when available
+func callerIdentity(creds *credentials.Credentials) string {
+	if sess, err := newAWSSession(creds); err == nil {
+		if out, err := sts.New(sess).GetCallerIdentity(&sts.GetCallerIdentityInput{}); err == nil && out.Arn != nil {
+			return *out.Arn
+		}
+	}
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return "unknown"
+}
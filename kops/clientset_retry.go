@@ -0,0 +1,210 @@
+package kops
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/kops/pkg/apis/kops"
+	kopsinternalversion "k8s.io/kops/pkg/client/clientset_generated/clientset/typed/kops/internalversion"
+	"k8s.io/kops/pkg/client/simple"
+)
+
+// retryClientset retries the cluster and instance group operations of the wrapped clientset on transient errors,
+// the retries stop at the deadline when there is one
+type retryClientset struct {
+	simple.Clientset
+	backoff  wait.Backoff
+	deadline time.Time
+}
+
+// until returns a copy of the clientset that stops retrying at the deadline
+func (c *retryClientset) until(deadline time.Time) *retryClientset {
+	bounded := *c
+	bounded.deadline = deadline
+	return &bounded
+}
+
+func (c *retryClientset) GetCluster(name string) (cluster *kops.Cluster, err error) {
+	err = retry(c.backoff, c.deadline, func() error {
+		cluster, err = c.Clientset.GetCluster(name)
+		return err
+	})
+	return cluster, err
+}
+
+// CreateCluster retries the create like the other operations, a retried create failing because the cluster exists
+// returns the cluster instead, the attempt that failed with a transient error may have written it
+func (c *retryClientset) CreateCluster(cluster *kops.Cluster) (created *kops.Cluster, err error) {
+	attempts := 0
+	err = retry(c.backoff, c.deadline, func() error {
+		attempts++
+		created, err = c.Clientset.CreateCluster(cluster)
+		return err
+	})
+	if attempts > 1 && isAlreadyExists(err) {
+		return c.GetCluster(cluster.Name)
+	}
+	return created, err
+}
+
+func (c *retryClientset) UpdateCluster(cluster *kops.Cluster, status *kops.ClusterStatus) (updated *kops.Cluster, err error) {
+	err = retry(c.backoff, c.deadline, func() error {
+		updated, err = c.Clientset.UpdateCluster(cluster, status)
+		return err
+	})
+	return updated, err
+}
+
+func (c *retryClientset) ListClusters(options v1.ListOptions) (list *kops.ClusterList, err error) {
+	err = retry(c.backoff, c.deadline, func() error {
+		list, err = c.Clientset.ListClusters(options)
+		return err
+	})
+	return list, err
+}
+
+func (c *retryClientset) DeleteCluster(cluster *kops.Cluster) error {
+	return retry(c.backoff, c.deadline, func() error {
+		return c.Clientset.DeleteCluster(cluster)
+	})
+}
+
+func (c *retryClientset) InstanceGroupsFor(cluster *kops.Cluster) kopsinternalversion.InstanceGroupInterface {
+	return &retryInstanceGroups{
+		InstanceGroupInterface: c.Clientset.InstanceGroupsFor(cluster),
+		backoff:                c.backoff,
+		deadline:               c.deadline,
+	}
+}
+
+// retryInstanceGroups retries the operations of the wrapped instance group client on transient errors
+type retryInstanceGroups struct {
+	kopsinternalversion.InstanceGroupInterface
+	backoff  wait.Backoff
+	deadline time.Time
+}
+
+// Create returns the instance group when a retried create fails because it exists, like CreateCluster
+func (i *retryInstanceGroups) Create(ig *kops.InstanceGroup) (created *kops.InstanceGroup, err error) {
+	attempts := 0
+	err = retry(i.backoff, i.deadline, func() error {
+		attempts++
+		created, err = i.InstanceGroupInterface.Create(ig)
+		return err
+	})
+	if attempts > 1 && isAlreadyExists(err) {
+		return i.Get(ig.Name, v1.GetOptions{})
+	}
+	return created, err
+}
+
+func (i *retryInstanceGroups) Update(ig *kops.InstanceGroup) (updated *kops.InstanceGroup, err error) {
+	err = retry(i.backoff, i.deadline, func() error {
+		updated, err = i.InstanceGroupInterface.Update(ig)
+		return err
+	})
+	return updated, err
+}
+
+func (i *retryInstanceGroups) Delete(name string, options *v1.DeleteOptions) error {
+	return retry(i.backoff, i.deadline, func() error {
+		return i.InstanceGroupInterface.Delete(name, options)
+	})
+}
+
+func (i *retryInstanceGroups) Get(name string, options v1.GetOptions) (ig *kops.InstanceGroup, err error) {
+	err = retry(i.backoff, i.deadline, func() error {
+		ig, err = i.InstanceGroupInterface.Get(name, options)
+		return err
+	})
+	return ig, err
+}
+
+func (i *retryInstanceGroups) List(options v1.ListOptions) (list *kops.InstanceGroupList, err error) {
+	err = retry(i.backoff, i.deadline, func() error {
+		list, err = i.InstanceGroupInterface.List(options)
+		return err
+	})
+	return list, err
+}
+
+// retry runs fn until it succeeds, fails with a permanent error or the backoff steps are exhausted,
+// a retry that would wait past the deadline fails with the last error instead
+func retry(backoff wait.Backoff, deadline time.Time, fn func() error) error {
+	duration := backoff.Duration
+	for step := 1; ; step++ {
+		err := fn()
+		if err == nil || !isRetryable(err) || step >= backoff.Steps {
+			return err
+		}
+
+		delay := duration
+		if backoff.Jitter > 0.0 {
+			delay = wait.Jitter(duration, backoff.Jitter)
+		}
+		if !deadline.IsZero() && time.Now().Add(delay).After(deadline) {
+			return fmt.Errorf("timeout while retrying: %v", err)
+		}
+		time.Sleep(delay)
+		duration = time.Duration(float64(duration) * backoff.Factor)
+	}
+}
+
+// retryableAWSErrorCodes are the codes of the AWS errors of throttled or temporarily unavailable services
+var retryableAWSErrorCodes = []string{
+	"Throttling",
+	"SlowDown",
+	"RequestLimitExceeded",
+	"ServiceUnavailable",
+	"InternalError",
+}
+
+// isRetryable reports whether err is a transient error of the state store, throttling, timeouts and server errors.
+// Any other error, like validation errors or missing objects, fails right away.
+func isRetryable(err error) bool {
+	switch e := err.(type) {
+	case awserr.RequestFailure:
+		if e.StatusCode() >= 500 {
+			return true
+		}
+		return isRetryableAWSErrorCode(e.Code())
+	case awserr.Error:
+		return isRetryableAWSErrorCode(e.Code())
+	case net.Error:
+		return e.Temporary() || e.Timeout()
+	}
+	if errors.IsServerTimeout(err) || errors.IsTooManyRequests(err) {
+		return true
+	}
+
+	// the kops VFS wraps the errors of S3 with fmt, only their message tells them apart
+	message := err.Error()
+	for _, code := range retryableAWSErrorCodes {
+		if strings.Contains(message, code+": ") {
+			return true
+		}
+	}
+	return strings.Contains(message, "status code: 5")
+}
+
+// isAlreadyExists reports whether a create failed because the object exists, the VFS clientset returns the error
+// of the file it failed to create
+func isAlreadyExists(err error) bool {
+	return err != nil && (os.IsExist(err) || errors.IsAlreadyExists(err))
+}
+
+func isRetryableAWSErrorCode(code string) bool {
+	for _, retryable := range retryableAWSErrorCodes {
+		if code == retryable {
+			return true
+		}
+	}
+	return false
+}
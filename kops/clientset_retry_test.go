@@ -0,0 +1,147 @@
+package kops
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/apis/kops/validation"
+	kopsinternalversion "k8s.io/kops/pkg/client/clientset_generated/clientset/typed/kops/internalversion"
+)
+
+func TestIsRetryable(t *testing.T) {
+	resource := schema.GroupResource{Group: "kops", Resource: "clusters"}
+	tests := []struct {
+		name      string
+		err       error
+		retryable bool
+	}{
+		{"field error", field.Invalid(field.NewPath("spec", "networkCIDR"), "10.0.0.0", "invalid CIDR"), false},
+		{"field error list", field.ErrorList{field.Required(field.NewPath("spec", "subnets"), "")}.ToAggregate(), false},
+		{"already exists", fmt.Errorf("cluster %q already exists", "cluster.example.com"), false},
+		{"not found", errors.NewNotFound(resource, "cluster.example.com"), false},
+		{"access denied", awserr.New("AccessDenied", "Access Denied", nil), false},
+		{"throttling", awserr.New("Throttling", "Rate exceeded", nil), true},
+		{"slow down", awserr.NewRequestFailure(awserr.New("SlowDown", "Please reduce your request rate.", nil), 503, "id"), true},
+		{"server error", awserr.NewRequestFailure(awserr.New("UnknownError", "", nil), 500, "id"), true},
+		{"client error", awserr.NewRequestFailure(awserr.New("NoSuchBucket", "", nil), 404, "id"), false},
+		{"wrapped slow down", fmt.Errorf("error fetching %s: %v", "s3://bucket/cluster.example.com/config", awserr.New("SlowDown", "Please reduce your request rate.", nil)), true},
+		{"network timeout", &net.OpError{Op: "dial", Err: timeoutError{}}, true},
+		{"server timeout", errors.NewServerTimeout(resource, "get", 1), true},
+		{"too many requests", errors.NewTooManyRequests("slow down", 1), true},
+	}
+	for _, test := range tests {
+		if retryable := isRetryable(test.err); retryable != test.retryable {
+			t.Errorf("%s: isRetryable(%v) = %t, want %t", test.name, test.err, retryable, test.retryable)
+		}
+	}
+}
+
+func TestRetryValidationError(t *testing.T) {
+	calls := 0
+	err := retry(wait.Backoff{Duration: time.Millisecond, Factor: 1, Steps: 5}, time.Time{}, func() error {
+		calls++
+		return validation.ValidateCluster(&kops.Cluster{}, false)
+	})
+	if err == nil {
+		t.Fatal("expected the validation error of an empty cluster")
+	}
+	if calls != 1 {
+		t.Errorf("validation error was tried %d times, want 1", calls)
+	}
+}
+
+func TestRetryThrottling(t *testing.T) {
+	calls := 0
+	err := retry(wait.Backoff{Duration: time.Millisecond, Factor: 1, Steps: 5}, time.Time{}, func() error {
+		calls++
+		if calls < 3 {
+			return awserr.New("Throttling", "Rate exceeded", nil)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("throttled call was tried %d times, want 3", calls)
+	}
+}
+
+func TestRetryDeadline(t *testing.T) {
+	calls := 0
+	err := retry(wait.Backoff{Duration: time.Hour, Factor: 1, Steps: 5}, time.Now().Add(time.Minute), func() error {
+		calls++
+		return awserr.New("Throttling", "Rate exceeded", nil)
+	})
+	if err == nil {
+		t.Fatal("expected a timeout once the next retry is past the deadline")
+	}
+	if calls != 1 {
+		t.Errorf("throttled call was tried %d times past the deadline, want 1", calls)
+	}
+}
+
+func TestRetryCreateAlreadyExists(t *testing.T) {
+	tests := []struct {
+		name    string
+		errs    []error
+		created bool
+	}{
+		{"created", []error{nil}, true},
+		{"created by the throttled attempt", []error{awserr.New("Throttling", "Rate exceeded", nil), os.ErrExist}, true},
+		{"exists before the first attempt", []error{os.ErrExist}, false},
+	}
+	for _, test := range tests {
+		igs := &fakeInstanceGroups{errs: test.errs}
+		retrying := &retryInstanceGroups{
+			InstanceGroupInterface: igs,
+			backoff:                wait.Backoff{Duration: time.Millisecond, Factor: 1, Steps: 5},
+		}
+		ig, err := retrying.Create(&kops.InstanceGroup{ObjectMeta: v1.ObjectMeta{Name: "nodes"}})
+		if created := err == nil && ig != nil && ig.Name == "nodes"; created != test.created {
+			t.Errorf("%s: Create returned %v, %v, want created %t", test.name, ig, err, test.created)
+		}
+	}
+}
+
+// fakeInstanceGroups fails the creates with errs in order, the instance group is stored before the error is returned
+type fakeInstanceGroups struct {
+	kopsinternalversion.InstanceGroupInterface
+	errs   []error
+	stored *kops.InstanceGroup
+}
+
+func (f *fakeInstanceGroups) Create(ig *kops.InstanceGroup) (*kops.InstanceGroup, error) {
+	err := f.errs[0]
+	f.errs = f.errs[1:]
+	if err == nil || !os.IsExist(err) {
+		f.stored = ig
+	}
+	if err != nil {
+		return nil, err
+	}
+	return ig, nil
+}
+
+func (f *fakeInstanceGroups) Get(name string, options v1.GetOptions) (*kops.InstanceGroup, error) {
+	if f.stored == nil || f.stored.Name != name {
+		return nil, errors.NewNotFound(schema.GroupResource{Group: "kops", Resource: "instancegroups"}, name)
+	}
+	return f.stored, nil
+}
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
@@ -11,8 +11,10 @@ func dataSourceCluster() *schema.Resource {
 			State: schema.ImportStatePassthrough,
 		},
 		Schema: map[string]*schema.Schema{
-			"metadata": schemaMetadata(),
-			"spec":     schemaClusterSpec(),
+			"metadata":             schemaMetadata(),
+			"spec":                 schemaClusterSpec(),
+			"computed_spec":        schemaClusterComputedSpec(),
+			"needs_rolling_update": schemaBoolComputed(),
 		},
 	}
 }
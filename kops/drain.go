@@ -0,0 +1,175 @@
+package kops
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	policy "k8s.io/api/policy/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/kops/pkg/apis/kops"
+)
+
+const (
+	drainPollInterval = 5 * time.Second
+
+	mirrorPodAnnotation = "kubernetes.io/config.mirror"
+)
+
+// newKubernetesClient builds a client of the API server of a cluster, authenticating with the kubeconfig context
+// named after the cluster, the one kops export kubecfg writes.
+func newKubernetesClient(clusterName string, m interface{}) (kubernetes.Interface, error) {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if kubeconfigPath := m.(*ProviderConfig).kubeconfigPath; kubeconfigPath != "" {
+		rules.ExplicitPath = kubeconfigPath
+	}
+	overrides := &clientcmd.ConfigOverrides{
+		CurrentContext: clusterName,
+	}
+
+	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, overrides).ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("error loading kubeconfig context %q: %v", clusterName, err)
+	}
+	return kubernetes.NewForConfig(config)
+}
+
+// drainInstanceGroup cordons the nodes of an instance group and evicts their pods, like kubectl drain --ignore-daemonsets --delete-local-data
+func drainInstanceGroup(client kubernetes.Interface, instanceGroupName string, postDrainDelay time.Duration, deadline time.Time) error {
+	nodes, err := client.CoreV1().Nodes().List(metav1.ListOptions{
+		LabelSelector: kops.NodeLabelInstanceGroup + "=" + instanceGroupName,
+	})
+	if err != nil {
+		return fmt.Errorf("error listing nodes of instance group %s: %v", instanceGroupName, err)
+	}
+
+	// cordon all nodes first, so the evicted pods don't land on the next node to drain
+	for _, node := range nodes.Items {
+		if err := cordonNode(client, node.Name); err != nil {
+			return err
+		}
+	}
+	for _, node := range nodes.Items {
+		log.Printf("[INFO] Draining node %s of instance group %s", node.Name, instanceGroupName)
+		if err := drainNode(client, node.Name, deadline); err != nil {
+			return err
+		}
+	}
+
+	if len(nodes.Items) > 0 && postDrainDelay > 0 {
+		log.Printf("[INFO] Waiting %s for pods to stabilize after draining instance group %s", postDrainDelay, instanceGroupName)
+		time.Sleep(postDrainDelay)
+	}
+	return nil
+}
+
+func cordonNode(client kubernetes.Interface, name string) error {
+	patch := []byte(`{"spec":{"unschedulable":true}}`)
+	if _, err := client.CoreV1().Nodes().Patch(name, types.StrategicMergePatchType, patch); err != nil {
+		return fmt.Errorf("error cordoning node %s: %v", name, err)
+	}
+	return nil
+}
+
+// drainNode evicts the pods of a node and waits for them to terminate
+func drainNode(client kubernetes.Interface, name string, deadline time.Time) error {
+	pods, err := client.CoreV1().Pods(metav1.NamespaceAll).List(metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("spec.nodeName", name).String(),
+	})
+	if err != nil {
+		return fmt.Errorf("error listing pods of node %s: %v", name, err)
+	}
+
+	var evictable []corev1.Pod
+	for _, pod := range pods.Items {
+		if evictablePod(pod) {
+			evictable = append(evictable, pod)
+		}
+	}
+	if err := evictPods(client, name, evictable, deadline); err != nil {
+		return err
+	}
+	return waitForPodsDeletion(client, name, evictable, deadline)
+}
+
+// evictablePod skips the pods drain leaves alone, mirror pods of static manifests, pods of daemon sets and finished pods
+func evictablePod(pod corev1.Pod) bool {
+	if _, ok := pod.Annotations[mirrorPodAnnotation]; ok {
+		return false
+	}
+	if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+		return false
+	}
+	if controller := metav1.GetControllerOf(&pod); controller != nil && controller.Kind == "DaemonSet" {
+		return false
+	}
+	return true
+}
+
+// evictPods evicts pods through the eviction API, retrying the pods their disruption budgets don't allow to evict yet
+func evictPods(client kubernetes.Interface, nodeName string, pods []corev1.Pod, deadline time.Time) error {
+	for len(pods) > 0 && time.Now().Before(deadline) {
+		var blocked []corev1.Pod
+		for _, pod := range pods {
+			err := client.CoreV1().Pods(pod.Namespace).Evict(&policy.Eviction{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: pod.Namespace,
+					Name:      pod.Name,
+				},
+			})
+			switch {
+			case err == nil, errors.IsNotFound(err):
+			case errors.IsTooManyRequests(err):
+				blocked = append(blocked, pod)
+			default:
+				return fmt.Errorf("error evicting pod %s/%s: %v", pod.Namespace, pod.Name, err)
+			}
+		}
+		if pods = blocked; len(pods) > 0 {
+			log.Printf("[DEBUG] Eviction of pods %s is not allowed by their disruption budgets yet, retrying", podNames(pods))
+			time.Sleep(drainPollInterval)
+		}
+	}
+	if len(pods) > 0 {
+		return fmt.Errorf("timeout while draining node %s, the disruption budgets of pods %s don't allow their eviction", nodeName, podNames(pods))
+	}
+	return nil
+}
+
+func waitForPodsDeletion(client kubernetes.Interface, nodeName string, pods []corev1.Pod, deadline time.Time) error {
+	for len(pods) > 0 && time.Now().Before(deadline) {
+		var running []corev1.Pod
+		for _, pod := range pods {
+			current, err := client.CoreV1().Pods(pod.Namespace).Get(pod.Name, metav1.GetOptions{})
+			if errors.IsNotFound(err) || (err == nil && current.UID != pod.UID) {
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("error waiting for pod %s/%s to terminate: %v", pod.Namespace, pod.Name, err)
+			}
+			running = append(running, pod)
+		}
+		if pods = running; len(pods) > 0 {
+			time.Sleep(drainPollInterval)
+		}
+	}
+	if len(pods) > 0 {
+		return fmt.Errorf("timeout while draining node %s, pods %s did not terminate", nodeName, podNames(pods))
+	}
+	return nil
+}
+
+func podNames(pods []corev1.Pod) string {
+	var names []string
+	for _, pod := range pods {
+		names = append(names, pod.Namespace+"/"+pod.Name)
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,54 @@
+package kops
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	policy "k8s.io/api/policy/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	core "k8s.io/client-go/testing"
+)
+
+func TestEvictPodsTimeout(t *testing.T) {
+	pods := []corev1.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web-1"}},
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "kube-system", Name: "dns-1"}},
+	}
+	client := fake.NewSimpleClientset()
+	client.PrependReactor("create", "pods", func(action core.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "eviction" {
+			return false, nil, nil
+		}
+		if action.(core.CreateAction).GetObject().(*policy.Eviction).Name == "web-1" {
+			return true, nil, errors.NewTooManyRequests("Cannot evict pod as it would violate the pod's disruption budget.", 0)
+		}
+		return true, nil, nil
+	})
+
+	err := evictPods(client, "node-1", pods, time.Now().Add(time.Second))
+	if err == nil {
+		t.Fatal("expected a timeout error")
+	}
+	if !strings.Contains(err.Error(), "default/web-1") || strings.Contains(err.Error(), "kube-system/dns-1") {
+		t.Errorf("expected the error to name only the blocked pod, got %v", err)
+	}
+}
+
+func TestWaitForPodsDeletionTimeout(t *testing.T) {
+	running := corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web-1", UID: "1"}}
+	deleted := corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web-2", UID: "2"}}
+	client := fake.NewSimpleClientset(&running)
+
+	err := waitForPodsDeletion(client, "node-1", []corev1.Pod{running, deleted}, time.Now().Add(time.Second))
+	if err == nil {
+		t.Fatal("expected a timeout error")
+	}
+	if !strings.Contains(err.Error(), "default/web-1") || strings.Contains(err.Error(), "default/web-2") {
+		t.Errorf("expected the error to name only the running pod, got %v", err)
+	}
+}
@@ -0,0 +1,19 @@
+package kops
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func isDryRun(m interface{}) bool {
+	return m.(*ProviderConfig).dryRun
+}
+
+// dryRunError reports the kops object an operation would have written to the state store, failing the operation
+// keeps Terraform from recording a state the state store doesn't have
+func dryRunError(operation, kind string, obj v1.Object) error {
+	s, _ := json.Marshal(obj)
+	return fmt.Errorf("dry run, skipped %s of %s %q: %s", operation, kind, obj.GetName(), string(s))
+}
@@ -2,25 +2,88 @@ package kops
 
 import (
 	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/terraform"
 	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/flowcontrol"
+	kopsapi "k8s.io/kops/pkg/apis/kops"
 	"k8s.io/kops/pkg/client/simple"
 	"k8s.io/kops/pkg/client/simple/vfsclientset"
+	"k8s.io/kops/pkg/featureflag"
 	"k8s.io/kops/util/pkg/vfs"
 )
 
 const (
-	invalidStateError = `Unable to read state store s3 bucket.
-Please use a valid s3 bucket uri on state_store attribute or KOPS_STATE_STORE env var.
-A valid value follows the format s3://<bucket>.
+	invalidStateError = `Unable to read state store bucket.
+Please use a valid bucket uri on state_store attribute or KOPS_STATE_STORE env var.
+A valid value follows the format s3://<bucket>, gs://<bucket> or do://<space>.
 Trailing slash will be trimmed.`
+	stateStoreNotFoundError     = "state store %q not found, please make sure the bucket exists: %v"
+	stateStoreAccessDeniedError = "access denied to state store %q, please check the credentials and bucket policy: %v"
 )
 
+// unsupportedStateStoreSchemes are the state stores of newer kops versions the vendored kops 1.10 can't read,
+// they are rejected when validating state_store instead of failing with an unknown path type
+var unsupportedStateStoreSchemes = []string{"azureblob://"}
+
+// memfsOnce initializes the in-memory VFS backing memfs:// state stores
+var memfsOnce sync.Once
+
 // ProviderConfig kops provider config structure
 type ProviderConfig struct {
-	stateStore string
-	clientset  simple.Clientset
+	stateStore         string
+	defaultClusterName string
+	clientset          simple.Clientset
+	backoff            wait.Backoff
+	dryRun             bool
+	assets             *kopsapi.Assets
+	cloudLabels        map[string]string
+	awsRateLimiter     flowcontrol.RateLimiter
+	auditLog           *auditLog
+	timeouts           map[string]time.Duration
+	kubeconfigPath     string
+	kubeconfigContext  string
+	// requiredKopsVersion enables the Kubernetes version guard of clusters
+	requiredKopsVersion string
+	// awsCredentials are the credentials of the assumed role or MFA session, nil when the default credential chain applies
+	awsCredentials *credentials.Credentials
+	// awsCredentialsExpiry is when the credentials exported for the kops S3 state store client expire, zero when they don't
+	awsCredentialsExpiry time.Time
+	// s3Endpoint and spacesEndpoint are the endpoints of the kops S3 client for s3:// and do:// state stores
+	s3Endpoint     string
+	spacesEndpoint string
+	// mutex guards clientsets
+	mutex sync.Mutex
+	// clientsets caches the clients of state stores overridden on resources
+	clientsets map[string]simple.Clientset
+}
+
+// clientsetFor returns the clientset of the given state store, the provider one is used when it is empty
+func (c *ProviderConfig) clientsetFor(registryPath string) (simple.Clientset, error) {
+	if registryPath == "" || registryPath == c.stateStore {
+		return c.clientset, nil
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if clientset, ok := c.clientsets[registryPath]; ok {
+		return clientset, nil
+	}
+
+	clientset, err := c.newClientset(registryPath)
+	if err != nil {
+		return nil, err
+	}
+	c.clientsets[registryPath] = clientset
+	return clientset, nil
 }
 
 // Provider exported for main package
@@ -28,10 +91,156 @@ func Provider() terraform.ResourceProvider {
 	return &schema.Provider{
 		Schema: map[string]*schema.Schema{
 			"state_store": {
+				Type:         schema.TypeString,
+				Required:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("KOPS_STATE_STORE", nil),
+				ValidateFunc: validateStateStore,
+				Description:  descriptions["state_store"],
+			},
+			"default_cluster_name": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("KOPS_CLUSTER_NAME", nil),
+				Description: descriptions["default_cluster_name"],
+			},
+			"feature_flags": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: descriptions["feature_flags"],
+			},
+			"http_proxy": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.MultiEnvDefaultFunc([]string{"HTTP_PROXY", "http_proxy"}, nil),
+				Description: descriptions["http_proxy"],
+			},
+			"https_proxy": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.MultiEnvDefaultFunc([]string{"HTTPS_PROXY", "https_proxy"}, nil),
+				Description: descriptions["https_proxy"],
+			},
+			"no_proxy": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.MultiEnvDefaultFunc([]string{"NO_PROXY", "no_proxy"}, nil),
+				Description: descriptions["no_proxy"],
+			},
+			"required_kops_channel_version": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: descriptions["required_kops_channel_version"],
+			},
+			"default_cloud_labels": {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				Description: descriptions["default_cloud_labels"],
+			},
+			"assets":   schemaProviderAssets(),
+			"timeouts": schemaProviderTimeouts(),
+			"audit_log": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: descriptions["audit_log"],
+			},
+			"dry_run": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: descriptions["dry_run"],
+			},
+			"max_retries": {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Default:     3,
+				Description: descriptions["max_retries"],
+			},
+			"retry_delay": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "1s",
+				ValidateFunc: validateDuration,
+				Description:  descriptions["retry_delay"],
+			},
+			"state_store_kms_key_id": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: descriptions["state_store_kms_key_id"],
+			},
+			"assume_role": schemaAssumeRole(),
+			"aws_api_qps": {
+				Type:        schema.TypeFloat,
+				Optional:    true,
+				Default:     0.0,
+				Description: descriptions["aws_api_qps"],
+			},
+			"aws_api_burst": {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Default:     10,
+				Description: descriptions["aws_api_burst"],
+			},
+			"profile": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("AWS_PROFILE", nil),
+				Description: descriptions["profile"],
+			},
+			"shared_credentials_file": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("AWS_SHARED_CREDENTIALS_FILE", nil),
+				Description: descriptions["shared_credentials_file"],
+			},
+			"s3_endpoint": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				DefaultFunc:   schema.EnvDefaultFunc("S3_ENDPOINT", nil),
+				Description:   descriptions["s3_endpoint"],
+				ConflictsWith: []string{"assume_role", "mfa_serial"},
+			},
+			"token": {
 				Type:        schema.TypeString,
-				Required:    true,
-				DefaultFunc: schema.EnvDefaultFunc("KOPS_STATE_STORE", nil),
-				Description: descriptions["state_store"],
+				Optional:    true,
+				Sensitive:   true,
+				DefaultFunc: schema.EnvDefaultFunc("AWS_SESSION_TOKEN", nil),
+				Description: descriptions["token"],
+			},
+			"mfa_serial": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: descriptions["mfa_serial"],
+			},
+			"mfa_token_code": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Sensitive:   true,
+				Description: descriptions["mfa_token_code"],
+			},
+			"mfa_session_duration": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "12h",
+				ValidateFunc: validateDuration,
+				Description:  descriptions["mfa_session_duration"],
+			},
+			"google_credentials": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.MultiEnvDefaultFunc([]string{"GOOGLE_CREDENTIALS", "GOOGLE_CLOUD_KEYFILE_JSON"}, nil),
+				Description: descriptions["google_credentials"],
+			},
+			"spaces": schemaSpaces(),
+			"kubeconfig_path": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: descriptions["kubeconfig_path"],
+			},
+			"kubeconfig_context": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: descriptions["kubeconfig_context"],
 			},
 		},
 		DataSourcesMap: map[string]*schema.Resource{
@@ -46,30 +255,481 @@ func Provider() terraform.ResourceProvider {
 	}
 }
 
+func schemaAssumeRole() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Optional:    true,
+		MaxItems:    1,
+		Description: descriptions["assume_role"],
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"role_arn": {
+					Type:        schema.TypeString,
+					Required:    true,
+					Description: descriptions["role_arn"],
+				},
+				"session_name": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Description: descriptions["session_name"],
+				},
+				"external_id": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Description: descriptions["external_id"],
+				},
+				"duration": {
+					Type:         schema.TypeString,
+					Optional:     true,
+					Default:      "1h",
+					ValidateFunc: validateDuration,
+					Description:  descriptions["duration"],
+				},
+			},
+		},
+	}
+}
+
+func schemaProviderAssets() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Optional:    true,
+		MaxItems:    1,
+		Description: descriptions["assets"],
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"container_registry": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Description: descriptions["container_registry"],
+				},
+				"container_proxy": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Description: descriptions["container_proxy"],
+				},
+				"file_repository": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Description: descriptions["file_repository"],
+				},
+			},
+		},
+	}
+}
+
+func schemaProviderTimeouts() *schema.Schema {
+	timeout := func(key string) *schema.Schema {
+		return &schema.Schema{
+			Type:         schema.TypeString,
+			Optional:     true,
+			ValidateFunc: validateDuration,
+			Description:  descriptions["timeouts_"+key],
+		}
+	}
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Optional:    true,
+		MaxItems:    1,
+		Description: descriptions["timeouts"],
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				schema.TimeoutCreate: timeout(schema.TimeoutCreate),
+				schema.TimeoutUpdate: timeout(schema.TimeoutUpdate),
+				schema.TimeoutDelete: timeout(schema.TimeoutDelete),
+			},
+		},
+	}
+}
+
+func schemaSpaces() *schema.Schema {
+	return &schema.Schema{
+		Type:          schema.TypeList,
+		Optional:      true,
+		MaxItems:      1,
+		Description:   descriptions["spaces"],
+		ConflictsWith: []string{"s3_endpoint"},
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"endpoint": {
+					Type:        schema.TypeString,
+					Required:    true,
+					Description: descriptions["spaces_endpoint"],
+				},
+				"region": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Description: descriptions["spaces_region"],
+				},
+				"access_key_id": {
+					Type:        schema.TypeString,
+					Required:    true,
+					Description: descriptions["spaces_access_key_id"],
+				},
+				"secret_access_key": {
+					Type:        schema.TypeString,
+					Required:    true,
+					Sensitive:   true,
+					Description: descriptions["spaces_secret_access_key"],
+				},
+			},
+		},
+	}
+}
+
 func configureProvider(data *schema.ResourceData) (interface{}, error) {
 	registryPath := data.Get("state_store").(string)
 
+	requiredKopsVersion := data.Get("required_kops_channel_version").(string)
+	if err := checkKopsVersion(requiredKopsVersion); err != nil {
+		return nil, err
+	}
+
+	featureflag.ParseFlags(strings.Join(expandStringSlice(data.Get("feature_flags")), ","))
+
+	if err := configureProxy(data); err != nil {
+		return nil, err
+	}
+
+	s3Endpoint := data.Get("s3_endpoint").(string)
+	if s3Endpoint != "" && s3Endpoint == exportedSpacesEndpoint {
+		// the endpoint defaults to the environment, which holds the Spaces endpoint another provider exported
+		s3Endpoint = environmentS3Endpoint
+	}
+	spaces := data.Get("spaces").([]interface{})
+	if err := claimProviderS3Endpoints(registryPath, s3Endpoint, spacesEndpoint(spaces)); err != nil {
+		return nil, err
+	}
+
+	awsCredentials, awsCredentialsExpiry, err := configureAWSCredentials(data, s3Endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := configureGoogleCredentials(data.Get("google_credentials").(string)); err != nil {
+		return nil, err
+	}
+
+	if err := configureSpaces(spaces); err != nil {
+		return nil, err
+	}
+
+	if err := validateStateStoreEncryption(registryPath, data.Get("state_store_kms_key_id").(string), s3Endpoint, awsCredentials); err != nil {
+		return nil, err
+	}
+
+	delay, _ := time.ParseDuration(data.Get("retry_delay").(string))
+	backoff := wait.Backoff{
+		Duration: delay,
+		Factor:   2,
+		Steps:    data.Get("max_retries").(int) + 1,
+	}
+
+	config := &ProviderConfig{
+		backoff:              backoff,
+		dryRun:               data.Get("dry_run").(bool),
+		assets:               expandProviderAssets(data.Get("assets").([]interface{})),
+		cloudLabels:          expandStringMap(data.Get("default_cloud_labels")),
+		timeouts:             expandProviderTimeouts(data.Get("timeouts").([]interface{})),
+		stateStore:           registryPath,
+		defaultClusterName:   data.Get("default_cluster_name").(string),
+		kubeconfigPath:       data.Get("kubeconfig_path").(string),
+		kubeconfigContext:    data.Get("kubeconfig_context").(string),
+		requiredKopsVersion:  requiredKopsVersion,
+		clientsets:           make(map[string]simple.Clientset),
+		auditLog:             newAuditLog(data.Get("audit_log").(string), awsCredentials),
+		awsCredentials:       awsCredentials,
+		awsCredentialsExpiry: awsCredentialsExpiry,
+		s3Endpoint:           s3Endpoint,
+		spacesEndpoint:       spacesEndpoint(spaces),
+	}
+	if qps := data.Get("aws_api_qps").(float64); qps > 0 {
+		config.awsRateLimiter = flowcontrol.NewTokenBucketRateLimiter(float32(qps), data.Get("aws_api_burst").(int))
+	}
+
+	clientset, err := config.newClientset(registryPath)
+	if err != nil {
+		return nil, err
+	}
+	config.clientset = clientset
+
+	return config, nil
+}
+
+// configureProxy exports the egress proxy settings picked up by the HTTP clients of kops and the cloud SDKs
+func configureProxy(data *schema.ResourceData) error {
+	env := map[string]string{
+		"HTTP_PROXY":  data.Get("http_proxy").(string),
+		"HTTPS_PROXY": data.Get("https_proxy").(string),
+		"NO_PROXY":    data.Get("no_proxy").(string),
+	}
+	for key, val := range env {
+		if val == "" {
+			continue
+		}
+		if err := os.Setenv(key, val); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func expandProviderAssets(data []interface{}) *kopsapi.Assets {
+	if len(data) > 0 {
+		conv := data[0].(map[string]interface{})
+		assets := &kopsapi.Assets{}
+		if registry := conv["container_registry"].(string); registry != "" {
+			assets.ContainerRegistry = &registry
+		}
+		if proxy := conv["container_proxy"].(string); proxy != "" {
+			assets.ContainerProxy = &proxy
+		}
+		if repository := conv["file_repository"].(string); repository != "" {
+			assets.FileRepository = &repository
+		}
+		return assets
+	}
+	return nil
+}
+
+// applyProviderDefaults fills in the cluster settings configured on the provider the cluster spec leaves empty
+func applyProviderDefaults(cluster *kopsapi.Cluster, m interface{}) {
+	config := m.(*ProviderConfig)
+
+	cluster.Spec.CloudLabels = mergeCloudLabels(config.cloudLabels, cluster.Spec.CloudLabels)
+
+	if config.assets != nil {
+		if cluster.Spec.Assets == nil {
+			cluster.Spec.Assets = &kopsapi.Assets{}
+		}
+		if cluster.Spec.Assets.ContainerRegistry == nil {
+			cluster.Spec.Assets.ContainerRegistry = config.assets.ContainerRegistry
+		}
+		if cluster.Spec.Assets.ContainerProxy == nil {
+			cluster.Spec.Assets.ContainerProxy = config.assets.ContainerProxy
+		}
+		if cluster.Spec.Assets.FileRepository == nil {
+			cluster.Spec.Assets.FileRepository = config.assets.FileRepository
+		}
+	}
+}
+
+// applyInstanceGroupDefaults fills in the instance group settings configured on the provider the instance group spec leaves empty
+func applyInstanceGroupDefaults(instanceGroup *kopsapi.InstanceGroup, m interface{}) {
+	config := m.(*ProviderConfig)
+
+	instanceGroup.Spec.CloudLabels = mergeCloudLabels(config.cloudLabels, instanceGroup.Spec.CloudLabels)
+}
+
+// mergeCloudLabels merges the provider default cloud labels with the resource ones, the resource ones take precedence
+func mergeCloudLabels(defaults, labels map[string]string) map[string]string {
+	if len(defaults) == 0 {
+		return labels
+	}
+	merged := make(map[string]string, len(defaults)+len(labels))
+	for key, val := range defaults {
+		merged[key] = val
+	}
+	for key, val := range labels {
+		merged[key] = val
+	}
+	return merged
+}
+
+// withoutDefaultCloudLabels drops the provider default cloud labels the resource does not configure itself,
+// so they don't show up as a diff of the resource
+func withoutDefaultCloudLabels(labels map[string]string, configured interface{}, m interface{}) map[string]string {
+	defaults := m.(*ProviderConfig).cloudLabels
+	if len(defaults) == 0 {
+		return labels
+	}
+	own, _ := configured.(map[string]interface{})
+	result := make(map[string]string, len(labels))
+	for key, val := range labels {
+		if _, ok := own[key]; !ok && defaults[key] == val {
+			continue
+		}
+		result[key] = val
+	}
+	return result
+}
+
+// claimProviderS3Endpoints claims the endpoints of the kops S3 client the provider configures,
+// for its state store as well as for the s3_endpoint and spaces arguments
+func claimProviderS3Endpoints(registryPath, s3Endpoint, spacesEndpoint string) error {
+	if endpoint, ok := stateStoreS3Endpoint(registryPath, s3Endpoint, spacesEndpoint); ok {
+		if err := claimS3Endpoint(endpoint); err != nil {
+			return err
+		}
+	}
+	for _, endpoint := range []string{s3Endpoint, spacesEndpoint} {
+		if endpoint == "" {
+			continue
+		}
+		if err := claimS3Endpoint(endpoint); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getClientset returns the clientset for the state store of the resource
+func getClientset(d *schema.ResourceData, m interface{}) (simple.Clientset, error) {
+	registryPath, _ := d.Get("state_store").(string)
+	config := m.(*ProviderConfig)
+	if registryPath == "" {
+		registryPath = config.stateStore
+	}
+	if strings.HasPrefix(registryPath, "s3://") {
+		if err := checkAWSCredentialsExpiry(config.awsCredentialsExpiry); err != nil {
+			return nil, err
+		}
+	}
+	if endpoint, ok := stateStoreS3Endpoint(registryPath, config.s3Endpoint, config.spacesEndpoint); ok {
+		if err := claimS3Endpoint(endpoint); err != nil {
+			return nil, err
+		}
+	}
+	return config.clientsetFor(registryPath)
+}
+
+// getClientsetUntil returns the clientset of a resource operation that has to finish by the deadline
+func getClientsetUntil(d *schema.ResourceData, m interface{}, deadline time.Time) (simple.Clientset, error) {
+	clientset, err := getClientset(d, m)
+	if err != nil {
+		return nil, err
+	}
+	if retrying, ok := clientset.(*retryClientset); ok {
+		return retrying.until(deadline), nil
+	}
+	return clientset, nil
+}
+
+func (c *ProviderConfig) newClientset(registryPath string) (simple.Clientset, error) {
+	var clientset simple.Clientset
+	var err error
+	if strings.HasPrefix(registryPath, "k8s://") {
+		clientset, err = newRESTClientset(registryPath, c.kubeconfigPath, c.kubeconfigContext)
+	} else {
+		clientset, err = newVFSClientset(registryPath)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &retryClientset{
+		Clientset: clientset,
+		backoff:   c.backoff,
+	}, nil
+}
+
+func newVFSClientset(registryPath string) (simple.Clientset, error) {
+	if strings.HasPrefix(registryPath, "memfs://") {
+		memfsOnce.Do(func() {
+			vfs.Context.ResetMemfsContext(true)
+		})
+	}
+
 	basePath, err := vfs.Context.BuildVfsPath(registryPath)
 	if err != nil {
 		return nil, fmt.Errorf("error building path for %q: %v", registryPath, err)
 	}
 
-	if !vfs.IsClusterReadable(basePath) {
+	// local state stores are not readable by the cluster, but are fine for managing the kops objects
+	if fsPath, ok := basePath.(*vfs.FSPath); ok {
+		if err := os.MkdirAll(fsPath.Path(), 0755); err != nil {
+			return nil, fmt.Errorf("error creating state store directory %q: %v", fsPath.Path(), err)
+		}
+	} else if !vfs.IsClusterReadable(basePath) {
 		return nil, field.Invalid(field.NewPath("State Store"), registryPath, invalidStateError)
 	}
 
-	clientset := vfsclientset.NewVFSClientset(basePath, true)
+	if err := checkStateStore(basePath); err != nil {
+		return nil, err
+	}
 
-	return &ProviderConfig{
-		clientset:  clientset,
-		stateStore: registryPath,
-	}, nil
+	return vfsclientset.NewVFSClientset(basePath, true), nil
+}
+
+// checkStateStore lists the state store to fail fast when it is unreachable
+func checkStateStore(basePath vfs.Path) error {
+	if _, err := basePath.ReadDir(); err != nil {
+		msg := err.Error()
+		switch {
+		case strings.Contains(msg, "NoSuchBucket"), strings.Contains(msg, "notFound"):
+			return fmt.Errorf(stateStoreNotFoundError, basePath, err)
+		case strings.Contains(msg, "AccessDenied"), strings.Contains(msg, "forbidden"):
+			return fmt.Errorf(stateStoreAccessDeniedError, basePath, err)
+		}
+		return fmt.Errorf("error reading state store %q: %v", basePath, err)
+	}
+	return nil
+}
+
+func validateDuration(v interface{}, k string) ([]string, []error) {
+	if _, err := time.ParseDuration(v.(string)); err != nil {
+		return nil, []error{fmt.Errorf("%q: %v", k, err)}
+	}
+	return nil, nil
+}
+
+// validateStateStore rejects state store schemes the vendored kops VFS is unable to handle
+func validateStateStore(v interface{}, k string) ([]string, []error) {
+	value := v.(string)
+	for _, scheme := range unsupportedStateStoreSchemes {
+		if strings.HasPrefix(value, scheme) {
+			return nil, []error{fmt.Errorf("%q: %s state stores are not supported by the vendored kops version", k, scheme)}
+		}
+	}
+	return nil, nil
 }
 
 var descriptions map[string]string
 
 func init() {
 	descriptions = map[string]string{
-		"state_store": "Location of state storage.",
+		"state_store":                   "Location of state storage, azureblob:// state stores are not supported by the vendored kops 1.10.",
+		"default_cluster_name":          "Cluster name used by resources which omit cluster_name, defaults to KOPS_CLUSTER_NAME.",
+		"feature_flags":                 "kops feature flags to enable on top of KOPS_FEATURE_FLAGS, prefix a flag with - to disable it.",
+		"http_proxy":                    "Proxy used for HTTP requests to the state store and cloud APIs.",
+		"https_proxy":                   "Proxy used for HTTPS requests to the state store and cloud APIs.",
+		"no_proxy":                      "Comma separated list of hosts which bypass the proxy.",
+		"required_kops_channel_version": "Semver range the kops version of the provider has to satisfy, e.g. >=1.10.0 <1.11.0. Also rejects clusters with a Kubernetes version newer than kops supports.",
+		"default_cloud_labels":          "Cloud labels merged into the cloud labels of every cluster and instance group.",
+		"assets":                        "Asset locations applied to every cluster which does not configure them.",
+		"container_registry":            "Container registry mirror used for all images.",
+		"container_proxy":               "Container registry proxy used for all images.",
+		"file_repository":               "File repository mirror used for all file assets.",
+		"timeouts":                      "Default operation timeouts of all resources, overridden by the timeouts of a resource.",
+		"timeouts_create":               "Default create timeout.",
+		"timeouts_update":               "Default update timeout.",
+		"timeouts_delete":               "Default delete timeout.",
+		"audit_log":                     "Path of a file every create, update and delete performed against the state store is appended to as a JSON record.",
+		"dry_run":                       "Fail the create, update and delete operations with the kops objects they would write instead of writing them to the state store, the Terraform state is left untouched.",
+		"max_retries":                   "Maximum number of retries of state store operations failing with transient errors.",
+		"retry_delay":                   "Delay before the first retry, doubled on every subsequent retry.",
+		"state_store_kms_key_id":        "KMS key the s3:// state store bucket has to use for default SSE-KMS encryption.",
+		"assume_role":                   "AWS role to assume before accessing the state store.",
+		"role_arn":                      "ARN of the IAM role to assume.",
+		"session_name":                  "Session name to use when assuming the role.",
+		"external_id":                   "External identifier to use when assuming the role.",
+		"duration":                      "Duration of the sessions of the assumed role, the role is assumed again before they expire unless mfa_serial is set. The kops S3 state store client keeps the first session.",
+		"aws_api_qps":                   "Maximum rate of AWS API requests kops sends per second while populating specs, 0 disables the rate limit.",
+		"aws_api_burst":                 "Maximum burst of AWS API requests when aws_api_qps is set.",
+		"profile":                       "AWS shared credentials profile used to access the state store, defaults to AWS_PROFILE.",
+		"shared_credentials_file":       "Path to the AWS shared credentials file, defaults to AWS_SHARED_CREDENTIALS_FILE or ~/.aws/credentials.",
+		"s3_endpoint":                   "Custom endpoint of an S3 compatible s3:// state store, e.g. MinIO or localstack, defaults to S3_ENDPOINT.",
+		"token":                         "AWS session token to use together with the default AWS access keys, defaults to AWS_SESSION_TOKEN.",
+		"mfa_serial":                    "Serial number or ARN of the MFA device used to authenticate to AWS.",
+		"mfa_token_code":                "Current code of the MFA device, required when mfa_serial is set.",
+		"mfa_session_duration":          "Duration of the MFA authenticated session, it can't be renewed without a new code.",
+		"google_credentials":            "Path to or contents of a Google service account JSON key, defaults to GOOGLE_CREDENTIALS or application default credentials.",
+		"spaces":                        "DigitalOcean Spaces configuration used for do:// state stores.",
+		"spaces_endpoint":               "Spaces endpoint, e.g. https://nyc3.digitaloceanspaces.com.",
+		"spaces_region":                 "Spaces region, defaults to us-east-1.",
+		"spaces_access_key_id":          "Spaces access key ID.",
+		"spaces_secret_access_key":      "Spaces secret access key.",
+		"kubeconfig_path":               "Path to the kubeconfig used for k8s:// state stores, defaults to KUBECONFIG or ~/.kube/config.",
+		"kubeconfig_context":            "Context of the kubeconfig used for k8s:// state stores, defaults to the current context.",
 	}
 }
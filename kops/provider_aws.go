@@ -0,0 +1,465 @@
+package kops
+
+import (
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/aws/defaults"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/autoscaling"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/elb"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/route53"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/hashicorp/terraform/helper/schema"
+	kopsapi "k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/model"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/cloudup/awsup"
+)
+
+const (
+	awsRateLimitHandler   = "terraform-provider-kops/rate-limit"
+	awsCredentialsHandler = "terraform-provider-kops/credentials"
+
+	// assumeRoleExpiryWindow is how long before the session of an assumed role expires the role is assumed again
+	assumeRoleExpiryWindow = time.Minute
+)
+
+var (
+	// environmentAWSCredentials are the AWS credentials of the environment the plugin was started with,
+	// read before any provider exports the credentials it resolved to the environment
+	environmentAWSCredentials, environmentAWSCredentialsErr = credentials.NewEnvCredentials().Get()
+
+	// environmentS3Endpoint is the S3_ENDPOINT of the environment the plugin was started with
+	environmentS3Endpoint = os.Getenv("S3_ENDPOINT")
+
+	// exportedAWSSessionToken is the session token the providers last exported to the environment
+	exportedAWSSessionToken string
+
+	// awsEnvironmentMutex serializes the providers configuring their credentials, they share the environment
+	awsEnvironmentMutex sync.Mutex
+)
+
+// configureAWSCredentials resolves the credentials the provider uses for AWS state stores, nil when the default
+// credential chain applies. kops builds the sessions of its S3 state store client from the environment,
+// so the resulting credentials are exported there as well, until the returned expiry when they are temporary.
+func configureAWSCredentials(data *schema.ResourceData, s3Endpoint string) (*credentials.Credentials, time.Time, error) {
+	awsEnvironmentMutex.Lock()
+	defer awsEnvironmentMutex.Unlock()
+
+	profile, file := data.Get("profile").(string), data.Get("shared_credentials_file").(string)
+	if err := configureSharedCredentials(profile, file); err != nil {
+		return nil, time.Time{}, err
+	}
+
+	token := data.Get("token").(string)
+	if token != "" && token == exportedAWSSessionToken {
+		// the token defaults to the environment, which holds the session another provider exported
+		token = environmentAWSCredentials.SessionToken
+	}
+	if token != "" {
+		if err := os.Setenv("AWS_SESSION_TOKEN", token); err != nil {
+			return nil, time.Time{}, err
+		}
+	}
+
+	serial := data.Get("mfa_serial").(string)
+	tokenCode := data.Get("mfa_token_code").(string)
+	if serial != "" && tokenCode == "" {
+		return nil, time.Time{}, fmt.Errorf("mfa_token_code is required when mfa_serial is set")
+	}
+
+	var creds *credentials.Credentials
+	var expiry time.Time
+	var err error
+	if assumeRole := data.Get("assume_role").([]interface{}); len(assumeRole) > 0 {
+		creds, expiry, err = configureAssumeRole(assumeRole[0].(map[string]interface{}), profile, file, token, serial, tokenCode)
+	} else if serial != "" {
+		duration, _ := time.ParseDuration(data.Get("mfa_session_duration").(string))
+		creds, expiry, err = configureSessionToken(profile, file, token, serial, tokenCode, duration)
+	}
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	if err := configureS3Endpoint(s3Endpoint, creds, profile, file, token); err != nil {
+		return nil, time.Time{}, err
+	}
+	return creds, expiry, nil
+}
+
+// checkAWSCredentialsExpiry fails once the credentials exported for the kops S3 state store client expired,
+// the client reads them once and can't be handed the renewed session
+func checkAWSCredentialsExpiry(expiry time.Time) error {
+	if !expiry.IsZero() && time.Now().After(expiry) {
+		return fmt.Errorf("the AWS session exported for the kops S3 state store client expired at %s, the session duration has to cover the longest apply",
+			expiry.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// configureSharedCredentials selects the profile and credentials file the AWS SDK loads shared credentials from
+func configureSharedCredentials(profile, file string) error {
+	if profile != "" {
+		if err := os.Setenv("AWS_PROFILE", profile); err != nil {
+			return err
+		}
+	}
+	if file != "" {
+		if err := os.Setenv("AWS_SHARED_CREDENTIALS_FILE", file); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// configureS3Endpoint points the kops S3 client to an S3 compatible endpoint such as MinIO or localstack.
+// kops only accepts static credentials for custom endpoints and reads them once, so the default AWS credentials are
+// exported for it when they are static. Assumed roles, MFA sessions and other temporary credentials expire.
+func configureS3Endpoint(endpoint string, creds *credentials.Credentials, profile, file, token string) error {
+	if endpoint == "" {
+		return nil
+	}
+	if creds != nil {
+		return fmt.Errorf("S3 endpoint %q requires static credentials, it can't be used with assume_role or mfa_serial", endpoint)
+	}
+
+	sess, err := newAWSSession(nil)
+	if err != nil {
+		return err
+	}
+
+	value, err := defaultAWSCredentials(sess, profile, file, token).Get()
+	if err != nil {
+		return fmt.Errorf("error resolving credentials for S3 endpoint %q: %v", endpoint, err)
+	}
+	if value.SessionToken != "" {
+		return fmt.Errorf("S3 endpoint %q requires static credentials, the %s credentials are temporary", endpoint, value.ProviderName)
+	}
+
+	env := map[string]string{
+		"S3_ENDPOINT":          endpoint,
+		"S3_ACCESS_KEY_ID":     value.AccessKeyID,
+		"S3_SECRET_ACCESS_KEY": value.SecretAccessKey,
+	}
+	if os.Getenv("S3_REGION") == "" {
+		env["S3_REGION"] = awsRegion()
+	}
+	for key, val := range env {
+		if err := os.Setenv(key, val); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// configureAssumeRole exchanges the default AWS credentials for the credentials of the configured role,
+// the returned credentials assume the role again before its session expires. The MFA code can't be used twice,
+// so with an MFA device the role is assumed once and its session lasts for the given duration.
+func configureAssumeRole(conv map[string]interface{}, profile, file, token, serial, tokenCode string) (*credentials.Credentials, time.Time, error) {
+	roleARN := conv["role_arn"].(string)
+	duration, _ := time.ParseDuration(conv["duration"].(string))
+	if duration == 0 {
+		duration = stscreds.DefaultDuration
+	}
+
+	sess, err := newAWSSession(nil)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	client := sts.New(sess, aws.NewConfig().WithCredentials(defaultAWSCredentials(sess, profile, file, token)))
+	creds := stscreds.NewCredentialsWithClient(client, roleARN, func(p *stscreds.AssumeRoleProvider) {
+		p.Duration = duration
+		p.ExpiryWindow = assumeRoleExpiryWindow
+		if name := conv["session_name"].(string); name != "" {
+			p.RoleSessionName = name
+		}
+		if id := conv["external_id"].(string); id != "" {
+			p.ExternalID = aws.String(id)
+		}
+		if serial != "" {
+			p.SerialNumber = aws.String(serial)
+			p.TokenCode = aws.String(tokenCode)
+		}
+	})
+
+	expiry := time.Now().Add(duration)
+	value, err := creds.Get()
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("error assuming role %q: %v", roleARN, err)
+	}
+
+	if err := exportAWSCredentials(value.AccessKeyID, value.SecretAccessKey, value.SessionToken); err != nil {
+		return nil, time.Time{}, err
+	}
+	if serial != "" {
+		return credentials.NewStaticCredentialsFromCreds(value), expiry, nil
+	}
+	return creds, expiry, nil
+}
+
+// defaultAWSCredentials resolves the default AWS credentials of the provider from the environment the plugin was
+// started with instead of the environment the providers export their credentials to, so a role is assumed with the
+// credentials the provider was configured with, also after another provider exported its credentials
+func defaultAWSCredentials(sess *session.Session, profile, file, token string) *credentials.Credentials {
+	if environmentAWSCredentialsErr == nil {
+		value := environmentAWSCredentials
+		if token != "" {
+			value.SessionToken = token
+		}
+		return credentials.NewStaticCredentialsFromCreds(value)
+	}
+	return credentials.NewCredentials(&credentials.ChainProvider{
+		VerboseErrors: true,
+		Providers: []credentials.Provider{
+			&credentials.SharedCredentialsProvider{Filename: file, Profile: profile},
+			defaults.RemoteCredProvider(*sess.Config, sess.Handlers),
+		},
+	})
+}
+
+// configureSessionToken exchanges the default AWS credentials for MFA authenticated session credentials.
+// The MFA code can't be used twice, so the session can't be renewed and lasts for the given duration.
+func configureSessionToken(profile, file, token, serial, tokenCode string, duration time.Duration) (*credentials.Credentials, time.Time, error) {
+	sess, err := newAWSSession(nil)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	client := sts.New(sess, aws.NewConfig().WithCredentials(defaultAWSCredentials(sess, profile, file, token)))
+	out, err := client.GetSessionToken(&sts.GetSessionTokenInput{
+		SerialNumber:    aws.String(serial),
+		TokenCode:       aws.String(tokenCode),
+		DurationSeconds: aws.Int64(int64(duration / time.Second)),
+	})
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("error getting session token for MFA device %q: %v", serial, err)
+	}
+
+	accessKeyID, secretAccessKey, sessionToken := *out.Credentials.AccessKeyId, *out.Credentials.SecretAccessKey, *out.Credentials.SessionToken
+	if err := exportAWSCredentials(accessKeyID, secretAccessKey, sessionToken); err != nil {
+		return nil, time.Time{}, err
+	}
+	return credentials.NewStaticCredentials(accessKeyID, secretAccessKey, sessionToken), aws.TimeValue(out.Credentials.Expiration), nil
+}
+
+// validateStateStoreEncryption ensures objects written to an s3:// state store are encrypted with the given KMS key.
+// kops honours the default encryption of the bucket, so the bucket has to be set up with SSE-KMS using that key.
+func validateStateStoreEncryption(registryPath, kmsKeyID, s3Endpoint string, creds *credentials.Credentials) error {
+	if kmsKeyID == "" || !strings.HasPrefix(registryPath, "s3://") {
+		return nil
+	}
+
+	u, err := url.Parse(registryPath)
+	if err != nil {
+		return fmt.Errorf("invalid s3 path: %q", registryPath)
+	}
+	bucket := strings.TrimSuffix(u.Host, "/")
+
+	sess, err := newAWSSession(creds)
+	if err != nil {
+		return err
+	}
+
+	location, err := s3.New(sess, s3Config(s3Endpoint)).GetBucketLocation(&s3.GetBucketLocationInput{Bucket: aws.String(bucket)})
+	if err != nil {
+		return fmt.Errorf("error getting location of bucket %q: %v", bucket, err)
+	}
+
+	region := "us-east-1"
+	if location.LocationConstraint != nil && *location.LocationConstraint != "" {
+		region = *location.LocationConstraint
+		if region == "EU" {
+			region = "eu-west-1"
+		}
+	}
+
+	encryption, err := s3.New(sess, s3Config(s3Endpoint).WithRegion(region)).GetBucketEncryption(&s3.GetBucketEncryptionInput{Bucket: aws.String(bucket)})
+	if err != nil {
+		return fmt.Errorf("error reading default encryption of bucket %q: %v", bucket, err)
+	}
+
+	for _, rule := range encryption.ServerSideEncryptionConfiguration.Rules {
+		sse := rule.ApplyServerSideEncryptionByDefault
+		if sse == nil || aws.StringValue(sse.SSEAlgorithm) != s3.ServerSideEncryptionAwsKms {
+			continue
+		}
+		keyID := aws.StringValue(sse.KMSMasterKeyID)
+		if keyID == kmsKeyID || strings.HasSuffix(keyID, "/"+kmsKeyID) || strings.HasSuffix(kmsKeyID, "/"+keyID) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("default encryption of bucket %q is not SSE-KMS with key %q", bucket, kmsKeyID)
+}
+
+// configureAWSCloud throttles the AWS API requests kops sends for the cluster while populating specs and signs them
+// with the credentials of the provider. kops caches its AWS clients per region, so both are installed on those shared clients.
+func configureAWSCloud(cluster *kopsapi.Cluster, m interface{}) error {
+	config := m.(*ProviderConfig)
+	if config.awsRateLimiter == nil && config.awsCredentials == nil {
+		return nil
+	}
+	if kopsapi.CloudProviderID(cluster.Spec.CloudProvider) != kopsapi.CloudProviderAWS {
+		return nil
+	}
+
+	region, err := awsup.FindRegion(cluster)
+	if err != nil {
+		return err
+	}
+
+	cloud, err := awsup.NewAWSCloud(region, nil)
+	if err != nil {
+		return fmt.Errorf("error initializing AWS cloud for region %q: %v", region, err)
+	}
+
+	var named []request.NamedHandler
+	if limiter := config.awsRateLimiter; limiter != nil {
+		named = append(named, request.NamedHandler{
+			Name: awsRateLimitHandler,
+			Fn: func(r *request.Request) {
+				limiter.Accept()
+			},
+		})
+	}
+	if creds := config.awsCredentials; creds != nil {
+		named = append(named, request.NamedHandler{
+			Name: awsCredentialsHandler,
+			Fn: func(r *request.Request) {
+				r.Config.Credentials = creds
+			},
+		})
+	}
+	for _, handlers := range awsCloudHandlers(cloud) {
+		for _, handler := range named {
+			handlers.Sign.RemoveByName(handler.Name)
+			handlers.Sign.PushFrontNamed(handler)
+		}
+	}
+	return nil
+}
+
+// resizeAutoscalingGroup sets the size of the autoscaling group of an instance group right away,
+// instead of waiting for the next cluster update. The desired capacity is moved within the new bounds.
+func resizeAutoscalingGroup(cluster *kopsapi.Cluster, instanceGroup *kopsapi.InstanceGroup) error {
+	if kopsapi.CloudProviderID(cluster.Spec.CloudProvider) != kopsapi.CloudProviderAWS {
+		log.Printf("[WARN] Cloud only resize is only supported on AWS, the size of instance group %s changes on the next cluster update", instanceGroup.Name)
+		return nil
+	}
+
+	region, err := awsup.FindRegion(cluster)
+	if err != nil {
+		return err
+	}
+	cloud, err := awsup.NewAWSCloud(region, nil)
+	if err != nil {
+		return fmt.Errorf("error initializing AWS cloud for region %q: %v", region, err)
+	}
+
+	name := (&model.KopsModelContext{Cluster: cluster}).AutoscalingGroupName(instanceGroup)
+	groups, err := cloud.Autoscaling().DescribeAutoScalingGroups(&autoscaling.DescribeAutoScalingGroupsInput{
+		AutoScalingGroupNames: []*string{aws.String(name)},
+	})
+	if err != nil {
+		return fmt.Errorf("error describing autoscaling group %s: %v", name, err)
+	}
+	if len(groups.AutoScalingGroups) == 0 {
+		log.Printf("[WARN] Autoscaling group %s doesn't exist yet, it is created with the new size on the next cluster update", name)
+		return nil
+	}
+
+	minSize, maxSize := int64(fi.Int32Value(instanceGroup.Spec.MinSize)), int64(fi.Int32Value(instanceGroup.Spec.MaxSize))
+	input := &autoscaling.UpdateAutoScalingGroupInput{
+		AutoScalingGroupName: aws.String(name),
+		MinSize:              aws.Int64(minSize),
+		MaxSize:              aws.Int64(maxSize),
+	}
+	if desired := aws.Int64Value(groups.AutoScalingGroups[0].DesiredCapacity); desired < minSize {
+		input.DesiredCapacity = aws.Int64(minSize)
+	} else if desired > maxSize {
+		input.DesiredCapacity = aws.Int64(maxSize)
+	}
+	if _, err := cloud.Autoscaling().UpdateAutoScalingGroup(input); err != nil {
+		return fmt.Errorf("error resizing autoscaling group %s: %v", name, err)
+	}
+	return nil
+}
+
+func awsCloudHandlers(cloud awsup.AWSCloud) []*request.Handlers {
+	handlers := []*request.Handlers{&cloud.CloudFormation().Handlers}
+	if client, ok := cloud.EC2().(*ec2.EC2); ok {
+		handlers = append(handlers, &client.Handlers)
+	}
+	if client, ok := cloud.IAM().(*iam.IAM); ok {
+		handlers = append(handlers, &client.Handlers)
+	}
+	if client, ok := cloud.ELB().(*elb.ELB); ok {
+		handlers = append(handlers, &client.Handlers)
+	}
+	if client, ok := cloud.Autoscaling().(*autoscaling.AutoScaling); ok {
+		handlers = append(handlers, &client.Handlers)
+	}
+	if client, ok := cloud.Route53().(*route53.Route53); ok {
+		handlers = append(handlers, &client.Handlers)
+	}
+	return handlers
+}
+
+// newAWSSession starts a session with the given credentials, with the default credential chain when they are nil
+func newAWSSession(creds *credentials.Credentials) (*session.Session, error) {
+	config := aws.NewConfig().WithRegion(awsRegion())
+	if creds != nil {
+		config = config.WithCredentials(creds)
+	}
+
+	sess, err := session.NewSession(config)
+	if err != nil {
+		return nil, fmt.Errorf("error starting new AWS session: %v", err)
+	}
+	return sess, nil
+}
+
+// s3Config points the S3 clients of the provider to its custom S3 endpoint, the other AWS services keep their endpoints
+func s3Config(endpoint string) *aws.Config {
+	config := aws.NewConfig()
+	if endpoint != "" {
+		config = config.WithEndpoint(endpoint).WithS3ForcePathStyle(true)
+	}
+	return config
+}
+
+func exportAWSCredentials(accessKeyID, secretAccessKey, sessionToken string) error {
+	env := map[string]string{
+		"AWS_ACCESS_KEY_ID":     accessKeyID,
+		"AWS_SECRET_ACCESS_KEY": secretAccessKey,
+		"AWS_SESSION_TOKEN":     sessionToken,
+	}
+	for key, val := range env {
+		if err := os.Setenv(key, val); err != nil {
+			return err
+		}
+	}
+	exportedAWSSessionToken = sessionToken
+	return nil
+}
+
+func awsRegion() string {
+	if region := os.Getenv("AWS_REGION"); region != "" {
+		return region
+	}
+	return "us-east-1"
+}
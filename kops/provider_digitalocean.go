@@ -0,0 +1,84 @@
+package kops
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// claimedS3Endpoint is the endpoint of the kops S3 client claimed by the first s3:// or do:// state store, nil before.
+// kops reads the endpoint from S3_ENDPOINT and sends the requests of every s3:// and do:// state store of the plugin
+// to it, so the state stores of all providers have to agree on it.
+var claimedS3Endpoint *string
+
+// exportedSpacesEndpoint is the Spaces endpoint the providers last exported to the environment
+var exportedSpacesEndpoint string
+
+// configureSpaces exports the DigitalOcean Spaces settings the kops S3 client reads when resolving do:// paths
+func configureSpaces(data []interface{}) error {
+	if len(data) == 0 {
+		return nil
+	}
+	conv := data[0].(map[string]interface{})
+
+	awsEnvironmentMutex.Lock()
+	defer awsEnvironmentMutex.Unlock()
+
+	env := map[string]string{
+		"S3_ENDPOINT":          conv["endpoint"].(string),
+		"S3_ACCESS_KEY_ID":     conv["access_key_id"].(string),
+		"S3_SECRET_ACCESS_KEY": conv["secret_access_key"].(string),
+	}
+	if region := conv["region"].(string); region != "" {
+		env["S3_REGION"] = region
+	}
+	for key, val := range env {
+		if err := os.Setenv(key, val); err != nil {
+			return err
+		}
+	}
+	exportedSpacesEndpoint = env["S3_ENDPOINT"]
+	return nil
+}
+
+// spacesEndpoint returns the endpoint of the spaces block, empty when it is not set
+func spacesEndpoint(data []interface{}) string {
+	if len(data) == 0 {
+		return ""
+	}
+	return data[0].(map[string]interface{})["endpoint"].(string)
+}
+
+// stateStoreS3Endpoint returns the endpoint the kops S3 client needs for a state store, empty for AWS,
+// false for state stores that don't use the kops S3 client
+func stateStoreS3Endpoint(registryPath, s3Endpoint, spacesEndpoint string) (string, bool) {
+	switch {
+	case strings.HasPrefix(registryPath, "s3://"):
+		return s3Endpoint, true
+	case strings.HasPrefix(registryPath, "do://"):
+		return spacesEndpoint, true
+	}
+	return "", false
+}
+
+// claimS3Endpoint reserves the endpoint of the kops S3 client, it fails when another state store already claimed
+// a different endpoint
+func claimS3Endpoint(endpoint string) error {
+	awsEnvironmentMutex.Lock()
+	defer awsEnvironmentMutex.Unlock()
+
+	if claimedS3Endpoint != nil && *claimedS3Endpoint != endpoint {
+		return fmt.Errorf("the S3 endpoint %s conflicts with the S3 endpoint %s of another state store, "+
+			"kops sends the requests of all s3:// and do:// state stores to a single endpoint",
+			describeS3Endpoint(endpoint), describeS3Endpoint(*claimedS3Endpoint))
+	}
+	claimedS3Endpoint = &endpoint
+	return nil
+}
+
+func describeS3Endpoint(endpoint string) string {
+	if endpoint == "" {
+		return "of AWS"
+	}
+	return fmt.Sprintf("%q", endpoint)
+}
@@ -0,0 +1,35 @@
+package kops
+
+import (
+	"testing"
+)
+
+func TestClaimProviderS3Endpoints(t *testing.T) {
+	const spaces = "https://nyc3.digitaloceanspaces.com"
+	tests := []struct {
+		name      string
+		providers [][3]string
+		conflict  bool
+	}{
+		{"s3 state stores", [][3]string{{"s3://a", "", ""}, {"s3://b", "", ""}}, false},
+		{"spaces state stores", [][3]string{{"do://a", "", spaces}, {"do://b", "", spaces}}, false},
+		{"spaces and gcs state stores", [][3]string{{"do://a", "", spaces}, {"gs://b", "", ""}}, false},
+		{"spaces with an s3 state store", [][3]string{{"s3://a", "", spaces}}, true},
+		{"spaces and s3 endpoint", [][3]string{{"do://a", "http://localhost:9000", spaces}}, true},
+		{"aliased spaces and s3 providers", [][3]string{{"s3://a", "", ""}, {"gs://b", "", spaces}}, true},
+		{"aliased s3 endpoints", [][3]string{{"s3://a", "http://localhost:9000", ""}, {"s3://b", "", ""}}, true},
+	}
+	for _, test := range tests {
+		claimedS3Endpoint = nil
+		var err error
+		for _, provider := range test.providers {
+			if err = claimProviderS3Endpoints(provider[0], provider[1], provider[2]); err != nil {
+				break
+			}
+		}
+		if conflict := err != nil; conflict != test.conflict {
+			t.Errorf("%s: conflict = %t, want %t (%v)", test.name, conflict, test.conflict, err)
+		}
+	}
+	claimedS3Endpoint = nil
+}
@@ -0,0 +1,70 @@
+package kops
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+
+	"github.com/hashicorp/terraform/helper/pathorcontents"
+)
+
+var (
+	// googleCredentialsDir holds the service account keys written for kops, it is removed by Cleanup
+	googleCredentialsDir string
+	// googleCredentialsMutex guards googleCredentialsDir, aliased providers are configured concurrently
+	googleCredentialsMutex sync.Mutex
+)
+
+// configureGoogleCredentials points the application default credentials used by the kops GCS client
+// at the configured service account key. Without credentials the ambient ADC configuration is used.
+// kops builds its Google clients from GOOGLE_APPLICATION_CREDENTIALS only, so the key is written
+// to a private temporary directory.
+func configureGoogleCredentials(credentials string) error {
+	if credentials == "" {
+		return nil
+	}
+
+	contents, _, err := pathorcontents.Read(credentials)
+	if err != nil {
+		return fmt.Errorf("error loading google credentials: %v", err)
+	}
+	if !json.Valid([]byte(contents)) {
+		return fmt.Errorf("google credentials are not a valid JSON key file")
+	}
+
+	googleCredentialsMutex.Lock()
+	defer googleCredentialsMutex.Unlock()
+
+	if googleCredentialsDir == "" {
+		dir, err := ioutil.TempDir("", "terraform-provider-kops-google")
+		if err != nil {
+			return fmt.Errorf("error writing google credentials: %v", err)
+		}
+		googleCredentialsDir = dir
+	}
+
+	file, err := ioutil.TempFile(googleCredentialsDir, "credentials")
+	if err != nil {
+		return fmt.Errorf("error writing google credentials: %v", err)
+	}
+	defer file.Close()
+
+	if _, err := file.WriteString(contents); err != nil {
+		return fmt.Errorf("error writing google credentials: %v", err)
+	}
+
+	return os.Setenv("GOOGLE_APPLICATION_CREDENTIALS", file.Name())
+}
+
+// Cleanup removes the files the provider wrote for kops, it is called when the plugin exits
+func Cleanup() {
+	googleCredentialsMutex.Lock()
+	defer googleCredentialsMutex.Unlock()
+
+	if googleCredentialsDir != "" {
+		os.RemoveAll(googleCredentialsDir)
+		googleCredentialsDir = ""
+	}
+}
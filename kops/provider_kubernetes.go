@@ -0,0 +1,51 @@
+package kops
+
+import (
+	"fmt"
+	"net/url"
+
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	kopsclient "k8s.io/kops/pkg/client/clientset_generated/clientset"
+	"k8s.io/kops/pkg/client/simple"
+	"k8s.io/kops/pkg/client/simple/api"
+)
+
+// newRESTClientset builds a clientset backed by the kops API server referenced by a k8s:// state store,
+// authenticating with the credentials of the given kubeconfig context, the current one is used when it is empty.
+func newRESTClientset(registryPath, kubeconfigPath, kubeconfigContext string) (simple.Clientset, error) {
+	u, err := url.Parse(registryPath)
+	if err != nil {
+		return nil, fmt.Errorf("invalid kops server url: %q", registryPath)
+	}
+
+	overrides := &clientcmd.ConfigOverrides{
+		ClusterInfo: clientcmdapi.Cluster{
+			Server: "https://" + u.Host,
+		},
+		CurrentContext: kubeconfigContext,
+	}
+
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if kubeconfigPath != "" {
+		rules.ExplicitPath = kubeconfigPath
+	}
+
+	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, overrides).ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("error loading kubeconfig: %v", err)
+	}
+
+	kopsClient, err := kopsclient.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("error building kops API client: %v", err)
+	}
+
+	return &api.RESTClientset{
+		BaseURL: &url.URL{
+			Scheme: "k8s",
+			Host:   u.Host,
+		},
+		KopsClient: kopsClient.Kops(),
+	}, nil
+}
@@ -1,37 +1,70 @@
 package kops
 
 import (
+	"fmt"
+
+	"github.com/hashicorp/terraform/helper/customdiff"
 	"github.com/hashicorp/terraform/helper/schema"
+	"golang.org/x/crypto/ssh"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/kops/pkg/apis/kops"
 	"k8s.io/kops/pkg/assets"
+	"k8s.io/kops/pkg/client/simple"
+	"k8s.io/kops/pkg/dns"
+	"k8s.io/kops/upup/pkg/fi"
 	"k8s.io/kops/upup/pkg/fi/cloudup"
 )
 
 func resourceCluster() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceClusterCreate,
-		Read:   resourceClusterRead,
-		Update: resourceClusterUpdate,
-		Delete: resourceClusterDelete,
-		Exists: resourceClusterExists,
+		Create:        resourceClusterCreate,
+		Read:          resourceClusterRead,
+		Update:        resourceClusterUpdate,
+		Delete:        resourceClusterDelete,
+		Exists:        resourceClusterExists,
+		CustomizeDiff: customdiff.All(validateKubernetesVersion, validateGossipCluster, validateClusterStores, validateClusterSpec),
+		Timeouts:      resourceTimeouts(),
 		Importer: &schema.ResourceImporter{
 			State: schema.ImportStatePassthrough,
 		},
 		Schema: map[string]*schema.Schema{
-			"metadata": schemaMetadata(),
-			"spec":     schemaClusterSpec(),
+			"state_store":          schemaStateStore(),
+			"metadata":             schemaMetadata(),
+			"spec":                 schemaClusterSpec(),
+			"computed_spec":        schemaClusterComputedSpec(),
+			"needs_rolling_update": schemaBoolComputed(),
+			"encryption_config_content": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Sensitive:    true,
+				ValidateFunc: validateEncryptionConfig,
+			},
+			"ssh_public_key": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateSSHPublicKey,
+			},
 		},
 	}
 }
 
 func resourceClusterCreate(d *schema.ResourceData, m interface{}) error {
-	clientset := m.(*ProviderConfig).clientset
-
-	cluster, err := clientset.CreateCluster(&kops.Cluster{
+	cluster := &kops.Cluster{
 		ObjectMeta: expandObjectMeta(sectionData(d, "metadata")),
 		Spec:       expandClusterSpec(sectionData(d, "spec")),
-	})
+	}
+	applyProviderDefaults(cluster, m)
+	if isDryRun(m) {
+		return dryRunError("create", "cluster", cluster)
+	}
+
+	clientset, err := getClientsetUntil(d, m, operationDeadline(d, m, schema.TimeoutCreate))
+	if err != nil {
+		return err
+	}
+
+	cluster, err = clientset.CreateCluster(cluster)
 	if err != nil {
 		return err
 	}
@@ -41,6 +74,10 @@ func resourceClusterCreate(d *schema.ResourceData, m interface{}) error {
 		return err
 	}
 
+	if err := configureAWSCloud(cluster, m); err != nil {
+		return err
+	}
+
 	assetBuilder := assets.NewAssetBuilder(cluster, "")
 	fullCluster, err := cloudup.PopulateClusterSpec(clientset, cluster, assetBuilder)
 	if err != nil {
@@ -52,6 +89,22 @@ func resourceClusterCreate(d *schema.ResourceData, m interface{}) error {
 		return err
 	}
 
+	if content := d.Get("encryption_config_content").(string); content != "" {
+		if err := writeEncryptionConfig(clientset, fullCluster, content); err != nil {
+			return err
+		}
+	}
+
+	if key := d.Get("ssh_public_key").(string); key != "" {
+		if err := writeSSHPublicKey(clientset, fullCluster, key); err != nil {
+			return err
+		}
+	}
+
+	if err := audit(d, m, "create", "cluster", cluster.Name); err != nil {
+		return err
+	}
+
 	d.SetId(cluster.Name)
 
 	return resourceClusterRead(d, m)
@@ -65,10 +118,29 @@ func resourceClusterRead(d *schema.ResourceData, m interface{}) error {
 	if err := d.Set("metadata", flattenObjectMeta(cluster.ObjectMeta)); err != nil {
 		return err
 	}
+	cluster.Spec.CloudLabels = withoutDefaultCloudLabels(cluster.Spec.CloudLabels, d.Get("spec.0.cloud_labels"), m)
 	if err := d.Set("spec", flattenClusterSpec(cluster.Spec)); err != nil {
 		return err
 	}
-	return nil
+	if err := d.Set("computed_spec", flattenClusterComputedSpec(cluster.Spec)); err != nil {
+		return err
+	}
+
+	var instanceGroups []*kops.InstanceGroup
+	if kops.CloudProviderID(cluster.Spec.CloudProvider) == kops.CloudProviderAWS {
+		clientset, err := getClientset(d, m)
+		if err != nil {
+			return err
+		}
+		list, err := clientset.InstanceGroupsFor(cluster).List(v1.ListOptions{})
+		if err != nil {
+			return err
+		}
+		for i := range list.Items {
+			instanceGroups = append(instanceGroups, &list.Items[i])
+		}
+	}
+	return setNeedsRollingUpdate(d, cluster, instanceGroups, m)
 }
 
 func resourceClusterUpdate(d *schema.ResourceData, m interface{}) error {
@@ -77,28 +149,73 @@ func resourceClusterUpdate(d *schema.ResourceData, m interface{}) error {
 		return nil
 	}
 
-	clientset := m.(*ProviderConfig).clientset
-
-	_, err := clientset.UpdateCluster(&kops.Cluster{
+	cluster := &kops.Cluster{
 		ObjectMeta: expandObjectMeta(sectionData(d, "metadata")),
 		Spec:       expandClusterSpec(sectionData(d, "spec")),
-	}, nil)
+	}
+	applyProviderDefaults(cluster, m)
+	if isDryRun(m) {
+		// the partial state mode keeps the previous state of the failed update
+		d.Partial(true)
+		return dryRunError("update", "cluster", cluster)
+	}
 
+	clientset, err := getClientsetUntil(d, m, operationDeadline(d, m, schema.TimeoutUpdate))
 	if err != nil {
 		return err
 	}
 
+	if d.HasChange("spec.0.kubernetes_version") {
+		if err := configureAWSCloud(cluster, m); err != nil {
+			return err
+		}
+		o, _ := d.GetChange("spec.0.kubernetes_version")
+		if cluster, err = upgradeCluster(clientset, cluster, o.(string)); err != nil {
+			return err
+		}
+	}
+
+	_, err = clientset.UpdateCluster(cluster, nil)
+	if err != nil {
+		return err
+	}
+
+	if content := d.Get("encryption_config_content").(string); d.HasChange("encryption_config_content") && content != "" {
+		if err := writeEncryptionConfig(clientset, cluster, content); err != nil {
+			return err
+		}
+	}
+
+	if key := d.Get("ssh_public_key").(string); d.HasChange("ssh_public_key") && key != "" {
+		if err := writeSSHPublicKey(clientset, cluster, key); err != nil {
+			return err
+		}
+	}
+
+	if err := audit(d, m, "update", "cluster", cluster.Name); err != nil {
+		return err
+	}
+
 	return resourceClusterRead(d, m)
 }
 
 func resourceClusterDelete(d *schema.ResourceData, m interface{}) error {
-	clientset := m.(*ProviderConfig).clientset
+	clientset, err := getClientsetUntil(d, m, operationDeadline(d, m, schema.TimeoutDelete))
+	if err != nil {
+		return err
+	}
 	cluster, err := getCluster(d, m)
 	if err != nil {
 		return err
 	}
+	if isDryRun(m) {
+		return dryRunError("delete", "cluster", cluster)
+	}
 
-	return clientset.DeleteCluster(cluster)
+	if err := clientset.DeleteCluster(cluster); err != nil {
+		return err
+	}
+	return audit(d, m, "delete", "cluster", cluster.Name)
 }
 
 func resourceClusterExists(d *schema.ResourceData, m interface{}) (bool, error) {
@@ -114,11 +231,96 @@ func resourceClusterExists(d *schema.ResourceData, m interface{}) (bool, error)
 }
 
 func getCluster(d *schema.ResourceData, m interface{}) (*kops.Cluster, error) {
-	clientset := m.(*ProviderConfig).clientset
-	cluster, err := clientset.GetCluster(d.Id())
-	return cluster, err
+	clientset, err := getClientset(d, m)
+	if err != nil {
+		return nil, err
+	}
+	return clientset.GetCluster(d.Id())
 }
 
 func sectionData(d *schema.ResourceData, section string) map[string]interface{} {
 	return d.Get(section).([]interface{})[0].(map[string]interface{})
 }
+
+// writeEncryptionConfig stores the EncryptionConfiguration of the API server in the secret store of the cluster,
+// like kops create secret encryptionconfig --force
+func writeEncryptionConfig(clientset simple.Clientset, cluster *kops.Cluster, content string) error {
+	secretStore, err := clientset.SecretStore(cluster)
+	if err != nil {
+		return err
+	}
+
+	if _, err := secretStore.ReplaceSecret("encryptionconfig", &fi.Secret{Data: []byte(content)}); err != nil {
+		return fmt.Errorf("error writing encryptionconfig secret: %v", err)
+	}
+	return nil
+}
+
+// writeSSHPublicKey replaces the admin SSH public key of the cluster, like kops create secret sshpublickey admin
+func writeSSHPublicKey(clientset simple.Clientset, cluster *kops.Cluster, key string) error {
+	sshCredentialStore, err := clientset.SSHCredentialStore(cluster)
+	if err != nil {
+		return err
+	}
+
+	existing, err := sshCredentialStore.FindSSHPublicKeys(fi.SecretNameSSHPrimary)
+	if err != nil {
+		return fmt.Errorf("error reading SSH public keys: %v", err)
+	}
+	for _, credential := range existing {
+		if err := sshCredentialStore.DeleteSSHCredential(credential); err != nil {
+			return fmt.Errorf("error deleting SSH public key: %v", err)
+		}
+	}
+
+	if err := sshCredentialStore.AddSSHPublicKey(fi.SecretNameSSHPrimary, []byte(key)); err != nil {
+		return fmt.Errorf("error adding SSH public key: %v", err)
+	}
+	return nil
+}
+
+func validateSSHPublicKey(v interface{}, k string) ([]string, []error) {
+	if _, _, _, _, err := ssh.ParseAuthorizedKey([]byte(v.(string))); err != nil {
+		return nil, []error{fmt.Errorf("%q: unable to parse SSH public key: %v", k, err)}
+	}
+	return nil, nil
+}
+
+func validateEncryptionConfig(v interface{}, k string) ([]string, []error) {
+	var parsed map[string]interface{}
+	if err := kops.ParseRawYaml([]byte(v.(string)), &parsed); err != nil {
+		return nil, []error{fmt.Errorf("%q: unable to parse yaml: %v", k, err)}
+	}
+	return nil, nil
+}
+
+// validateClusterStores rejects moving the stores of an existing cluster, kops doesn't copy the objects already written to the old location.
+func validateClusterStores(d *schema.ResourceDiff, m interface{}) error {
+	if d.Id() == "" {
+		return nil
+	}
+
+	for _, key := range []string{"config_base", "key_store", "secret_store"} {
+		o, n := d.GetChange("spec.0." + key)
+		if o.(string) != "" && d.NewValueKnown("spec.0."+key) && o.(string) != n.(string) {
+			return fmt.Errorf("%s of cluster %s can't be changed from %q to %q", key, d.Id(), o, n)
+		}
+	}
+	return nil
+}
+
+// validateGossipCluster rejects settings that don't work with gossip DNS, used by kops for clusters named *.k8s.local.
+func validateGossipCluster(d *schema.ResourceDiff, m interface{}) error {
+	name, ok := d.Get("metadata.0.name").(string)
+	if !ok || !dns.IsGossipHostname(name) {
+		return nil
+	}
+
+	if zone, _ := d.Get("spec.0.dnszone").(string); zone != "" {
+		return fmt.Errorf("cluster %s uses gossip DNS, dnszone %q must not be set", name, zone)
+	}
+	if publishDNS, _ := d.Get("spec.0.api.0.dns").(bool); publishDNS {
+		return fmt.Errorf("cluster %s uses gossip DNS which is not resolvable outside of the cluster, publish the API with api load_balancer instead of dns", name)
+	}
+	return nil
+}
@@ -2,13 +2,19 @@ package kops
 
 import (
 	"fmt"
+	"log"
+	"reflect"
 	"strings"
+	"time"
 
+	"github.com/hashicorp/terraform/helper/customdiff"
 	"github.com/hashicorp/terraform/helper/schema"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/model"
 	"k8s.io/kops/upup/pkg/fi/cloudup"
+	"k8s.io/kops/upup/pkg/fi/cloudup/gce"
 )
 
 type instanceGroupID struct {
@@ -33,38 +39,117 @@ func parseInstanceGroupID(id string) instanceGroupID {
 
 func resourceInstanceGroup() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceInstanceGroupCreate,
-		Read:   resourceInstanceGroupRead,
-		Update: resourceInstanceGroupUpdate,
-		Delete: resourceInstanceGroupDelete,
-		Exists: resourceInstanceGroupExists,
+		Create:        resourceInstanceGroupCreate,
+		Read:          resourceInstanceGroupRead,
+		Update:        resourceInstanceGroupUpdate,
+		Delete:        resourceInstanceGroupDelete,
+		Exists:        resourceInstanceGroupExists,
+		CustomizeDiff: customdiff.All(validateInstanceGroupSize, validateInstanceGroupSpec, validateInstanceGroupImage),
+		Timeouts:      resourceTimeouts(),
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			State: resourceInstanceGroupImport,
 		},
 		Schema: map[string]*schema.Schema{
-			"cluster_name": schemaStringRequired(),
+			"state_store":  schemaStateStore(),
+			"cluster_name": schemaStringOptionalComputed(),
 			"metadata":     schemaMetadata(),
 			"spec":         schemaInstanceGroupSpec(),
+
+			"cluster_autoscaler":   schemaBoolOptional(),
+			"drain_on_destroy":     schemaBoolOptional(),
+			"drain_post_delay":     schemaDurationOptional(),
+			"cloud_only_resize":    schemaBoolOptional(),
+			"roll_on_image_change": schemaBoolOptional(),
+
+			"autoscaling_group_name":       schemaStringComputed(),
+			"needs_rolling_update":         schemaBoolComputed(),
+			"instance_group_manager_names": schemaStringSliceComputed(),
 		},
 	}
 }
 
+// resourceInstanceGroupImport imports the instance group of a <cluster name>/<instance group name> ID,
+// or all instance groups of the cluster of a <cluster name>/* ID
+func resourceInstanceGroupImport(d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	groupID := parseInstanceGroupID(d.Id())
+	if groupID.clusterName == "" || groupID.instanceGroupName == "" {
+		return nil, fmt.Errorf("invalid instance group ID %q, expected <cluster name>/<instance group name> or <cluster name>/*", d.Id())
+	}
+	if groupID.instanceGroupName != "*" {
+		return []*schema.ResourceData{d}, nil
+	}
+
+	clientset, err := getClientset(d, m)
+	if err != nil {
+		return nil, err
+	}
+	cluster, err := clientset.GetCluster(groupID.clusterName)
+	if err != nil {
+		return nil, err
+	}
+	instanceGroups, err := clientset.InstanceGroupsFor(cluster).List(v1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if len(instanceGroups.Items) == 0 {
+		return nil, fmt.Errorf("cluster %s has no instance groups to import", groupID.clusterName)
+	}
+
+	var results []*schema.ResourceData
+	for _, instanceGroup := range instanceGroups.Items {
+		data := resourceInstanceGroup().Data(nil)
+		data.SetType("kops_instance_group")
+		data.SetId(instanceGroupID{
+			clusterName:       groupID.clusterName,
+			instanceGroupName: instanceGroup.Name,
+		}.String())
+		if err := data.Set("state_store", d.Get("state_store")); err != nil {
+			return nil, err
+		}
+		results = append(results, data)
+	}
+	return results, nil
+}
+
 func resourceInstanceGroupCreate(d *schema.ResourceData, m interface{}) error {
-	clusterName := d.Get("cluster_name").(string)
-	clientset := m.(*ProviderConfig).clientset
+	clusterName, err := getClusterName(d, m)
+	if err != nil {
+		return err
+	}
+	if err := d.Set("cluster_name", clusterName); err != nil {
+		return err
+	}
+
+	clientset, err := getClientsetUntil(d, m, operationDeadline(d, m, schema.TimeoutCreate))
+	if err != nil {
+		return err
+	}
 	cluster, err := clientset.GetCluster(clusterName)
 	if err != nil {
 		return err
 	}
 
-	instanceGroup, err := clientset.InstanceGroupsFor(cluster).Create(&kops.InstanceGroup{
+	instanceGroup := &kops.InstanceGroup{
 		ObjectMeta: expandObjectMeta(sectionData(d, "metadata")),
 		Spec:       expandInstanceGroupSpec(sectionData(d, "spec")),
-	})
+	}
+	applyInstanceGroupDefaults(instanceGroup, m)
+	if d.Get("cluster_autoscaler").(bool) {
+		applyClusterAutoscalerLabels(instanceGroup, clusterName)
+	}
+	if isDryRun(m) {
+		return dryRunError("create", "instance group", instanceGroup)
+	}
+
+	instanceGroup, err = clientset.InstanceGroupsFor(cluster).Create(instanceGroup)
 	if err != nil {
 		return err
 	}
 
+	if err := configureAWSCloud(cluster, m); err != nil {
+		return err
+	}
+
 	channel, err := cloudup.ChannelForCluster(cluster)
 	if err != nil {
 		return err
@@ -80,6 +165,10 @@ func resourceInstanceGroupCreate(d *schema.ResourceData, m interface{}) error {
 		return err
 	}
 
+	if err := audit(d, m, "create", "instance group", instanceGroup.ObjectMeta.Name); err != nil {
+		return err
+	}
+
 	d.SetId(instanceGroupID{
 		clusterName:       clusterName,
 		instanceGroupName: instanceGroup.ObjectMeta.Name,
@@ -89,55 +178,144 @@ func resourceInstanceGroupCreate(d *schema.ResourceData, m interface{}) error {
 }
 
 func resourceInstanceGroupRead(d *schema.ResourceData, m interface{}) error {
-	instanceGroup, err := getInstanceGroup(d, m)
+	cluster, instanceGroup, err := getInstanceGroup(d, m)
 	if err != nil {
 		return err
 	}
+	if err := d.Set("cluster_name", parseInstanceGroupID(d.Id()).clusterName); err != nil {
+		return err
+	}
 	if err := d.Set("metadata", flattenObjectMeta(instanceGroup.ObjectMeta)); err != nil {
 		return err
 	}
+	instanceGroup.Spec.CloudLabels = withoutDefaultCloudLabels(instanceGroup.Spec.CloudLabels, d.Get("spec.0.cloud_labels"), m)
+	if d.Get("cluster_autoscaler").(bool) {
+		instanceGroup.Spec.CloudLabels = withoutClusterAutoscalerLabels(instanceGroup.Spec.CloudLabels, d.Get("spec.0.cloud_labels"), cluster.Name)
+	}
 	if err := d.Set("spec", flattenInstanceGroupSpec(instanceGroup.Spec)); err != nil {
 		return err
 	}
-	return nil
+	if err := setNeedsRollingUpdate(d, cluster, []*kops.InstanceGroup{instanceGroup}, m); err != nil {
+		return err
+	}
+	return setInstanceGroupCloudNames(d, cluster, instanceGroup)
 }
 
 func resourceInstanceGroupUpdate(d *schema.ResourceData, m interface{}) error {
+	deadline := operationDeadline(d, m, schema.TimeoutUpdate)
 	if ok, _ := resourceInstanceGroupExists(d, m); !ok {
 		d.SetId("")
 		return nil
 	}
 
-	clusterName := d.Get("cluster_name").(string)
-	clientset := m.(*ProviderConfig).clientset
+	clusterName, err := getClusterName(d, m)
+	if err != nil {
+		return err
+	}
+
+	clientset, err := getClientsetUntil(d, m, deadline)
+	if err != nil {
+		return err
+	}
 	cluster, err := clientset.GetCluster(clusterName)
 	if err != nil {
 		return err
 	}
 
-	_, err = clientset.InstanceGroupsFor(cluster).Update(&kops.InstanceGroup{
+	instanceGroup := &kops.InstanceGroup{
 		ObjectMeta: expandObjectMeta(sectionData(d, "metadata")),
 		Spec:       expandInstanceGroupSpec(sectionData(d, "spec")),
-	})
+	}
+	applyInstanceGroupDefaults(instanceGroup, m)
+	if d.Get("cluster_autoscaler").(bool) {
+		applyClusterAutoscalerLabels(instanceGroup, clusterName)
+	}
+	if isDryRun(m) {
+		// the partial state mode keeps the previous state of the failed update
+		d.Partial(true)
+		return dryRunError("update", "instance group", instanceGroup)
+	}
+
+	if err := configureAWSCloud(cluster, m); err != nil {
+		return err
+	}
+
+	channel, err := cloudup.ChannelForCluster(cluster)
 	if err != nil {
 		return err
 	}
 
+	// populate the spec like on create, writing the expanded spec alone drops the defaults kops filled in
+	fullInstanceGroup, err := cloudup.PopulateInstanceGroupSpec(cluster, instanceGroup, channel)
+	if err != nil {
+		return err
+	}
+
+	_, err = clientset.InstanceGroupsFor(cluster).Update(fullInstanceGroup)
+	if err != nil {
+		return err
+	}
+
+	if d.HasChange("spec.0.image") {
+		if d.Get("roll_on_image_change").(bool) {
+			// the previous image stays in the state until the roll succeeds, the next apply resumes a failed roll
+			d.Partial(true)
+			if err := rollInstanceGroupImage(clientset, cluster, fullInstanceGroup, m, deadline); err != nil {
+				return err
+			}
+			d.Partial(false)
+		} else {
+			oldImage, _ := d.GetChange("spec.0.image")
+			log.Printf("[WARN] Image of instance group %q changed from %s to %s, the instances are replaced by a rolling update only: kops rolling-update cluster %s --instance-group %s --yes",
+				instanceGroup.Name, oldImage, fullInstanceGroup.Spec.Image, cluster.Name, instanceGroup.Name)
+		}
+	}
+
+	if d.Get("cloud_only_resize").(bool) && onlySizeChanged(d) {
+		if err := resizeAutoscalingGroup(cluster, fullInstanceGroup); err != nil {
+			return err
+		}
+	}
+
+	if err := audit(d, m, "update", "instance group", instanceGroup.ObjectMeta.Name); err != nil {
+		return err
+	}
+
 	return resourceInstanceGroupRead(d, m)
 }
 
 func resourceInstanceGroupDelete(d *schema.ResourceData, m interface{}) error {
+	deadline := operationDeadline(d, m, schema.TimeoutDelete)
 	groupID := parseInstanceGroupID(d.Id())
-	clientset := m.(*ProviderConfig).clientset
+	clientset, err := getClientsetUntil(d, m, deadline)
+	if err != nil {
+		return err
+	}
 	cluster, err := clientset.GetCluster(groupID.clusterName)
 	if err != nil {
 		return err
 	}
-	return clientset.InstanceGroupsFor(cluster).Delete(groupID.instanceGroupName, &v1.DeleteOptions{})
+	if isDryRun(m) {
+		return fmt.Errorf("dry run, skipped delete of instance group %q", groupID)
+	}
+	if d.Get("drain_on_destroy").(bool) {
+		client, err := newKubernetesClient(cluster.Name, m)
+		if err != nil {
+			return err
+		}
+		postDrainDelay, _ := time.ParseDuration(d.Get("drain_post_delay").(string))
+		if err := drainInstanceGroup(client, groupID.instanceGroupName, postDrainDelay, deadline); err != nil {
+			return err
+		}
+	}
+	if err := clientset.InstanceGroupsFor(cluster).Delete(groupID.instanceGroupName, &v1.DeleteOptions{}); err != nil {
+		return err
+	}
+	return audit(d, m, "delete", "instance group", groupID.instanceGroupName)
 }
 
 func resourceInstanceGroupExists(d *schema.ResourceData, m interface{}) (bool, error) {
-	_, err := getInstanceGroup(d, m)
+	_, _, err := getInstanceGroup(d, m)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			return false, nil
@@ -148,12 +326,86 @@ func resourceInstanceGroupExists(d *schema.ResourceData, m interface{}) (bool, e
 	return true, nil
 }
 
-func getInstanceGroup(d *schema.ResourceData, m interface{}) (*kops.InstanceGroup, error) {
+func getClusterName(d *schema.ResourceData, m interface{}) (string, error) {
+	if clusterName := d.Get("cluster_name").(string); clusterName != "" {
+		return clusterName, nil
+	}
+	if clusterName := m.(*ProviderConfig).defaultClusterName; clusterName != "" {
+		return clusterName, nil
+	}
+	return "", fmt.Errorf("cluster_name is required when default_cluster_name is not set on the provider")
+}
+
+func getInstanceGroup(d *schema.ResourceData, m interface{}) (*kops.Cluster, *kops.InstanceGroup, error) {
 	groupID := parseInstanceGroupID(d.Id())
-	clientset := m.(*ProviderConfig).clientset
+	clientset, err := getClientset(d, m)
+	if err != nil {
+		return nil, nil, err
+	}
 	cluster, err := clientset.GetCluster(groupID.clusterName)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	instanceGroup, err := clientset.InstanceGroupsFor(cluster).Get(groupID.instanceGroupName, v1.GetOptions{})
+	return cluster, instanceGroup, err
+}
+
+// onlySizeChanged reports whether min_size or max_size are the only changes of the instance group spec
+func onlySizeChanged(d *schema.ResourceData) bool {
+	if !d.HasChange("spec.0.min_size") && !d.HasChange("spec.0.max_size") {
+		return false
+	}
+	oldData, newData := d.GetChange("spec")
+	oldSpec := expandInstanceGroupSpec(oldData.([]interface{})[0].(map[string]interface{}))
+	newSpec := expandInstanceGroupSpec(newData.([]interface{})[0].(map[string]interface{}))
+	oldSpec.MinSize, oldSpec.MaxSize = nil, nil
+	newSpec.MinSize, newSpec.MaxSize = nil, nil
+	return reflect.DeepEqual(oldSpec, newSpec)
+}
+
+// clusterAutoscalerLabels returns the cloud labels the cluster-autoscaler auto discovery looks for
+func clusterAutoscalerLabels(clusterName string) map[string]string {
+	return map[string]string{
+		"k8s.io/cluster-autoscaler/enabled":        "",
+		"k8s.io/cluster-autoscaler/" + clusterName: "",
+	}
+}
+
+// applyClusterAutoscalerLabels adds the cluster-autoscaler discovery labels to the cloud labels of the instance group
+func applyClusterAutoscalerLabels(instanceGroup *kops.InstanceGroup, clusterName string) {
+	instanceGroup.Spec.CloudLabels = mergeCloudLabels(clusterAutoscalerLabels(clusterName), instanceGroup.Spec.CloudLabels)
+}
+
+// withoutClusterAutoscalerLabels drops the cluster-autoscaler discovery labels the resource does not configure itself
+func withoutClusterAutoscalerLabels(labels map[string]string, configured interface{}, clusterName string) map[string]string {
+	own, _ := configured.(map[string]interface{})
+	result := make(map[string]string, len(labels))
+	for key, val := range labels {
+		if _, ok := own[key]; !ok {
+			if _, ok := clusterAutoscalerLabels(clusterName)[key]; ok {
+				continue
+			}
+		}
+		result[key] = val
+	}
+	return result
+}
+
+// setInstanceGroupCloudNames sets the names of the cloud resources kops creates for the instance group,
+// the autoscaling group on AWS and the instance group manager of each zone on GCE
+func setInstanceGroupCloudNames(d *schema.ResourceData, cluster *kops.Cluster, instanceGroup *kops.InstanceGroup) error {
+	var autoscalingGroupName string
+	var instanceGroupManagerNames []string
+	switch kops.CloudProviderID(cluster.Spec.CloudProvider) {
+	case kops.CloudProviderAWS:
+		autoscalingGroupName = (&model.KopsModelContext{Cluster: cluster}).AutoscalingGroupName(instanceGroup)
+	case kops.CloudProviderGCE:
+		for _, zone := range instanceGroup.Spec.Zones {
+			instanceGroupManagerNames = append(instanceGroupManagerNames, gce.NameForInstanceGroupManager(cluster, instanceGroup, zone))
+		}
+	}
+	if err := d.Set("autoscaling_group_name", autoscalingGroupName); err != nil {
+		return err
 	}
-	return clientset.InstanceGroupsFor(cluster).Get(groupID.instanceGroupName, v1.GetOptions{})
+	return d.Set("instance_group_manager_names", instanceGroupManagerNames)
 }
@@ -0,0 +1,65 @@
+package kops
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform/config"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func instanceGroupSpec(minSize, maxSize int, machineType string) map[string]interface{} {
+	return map[string]interface{}{
+		"spec": []interface{}{map[string]interface{}{
+			"role":         "Node",
+			"machine_type": machineType,
+			"min_size":     minSize,
+			"max_size":     maxSize,
+			"subnets":      []interface{}{"eu-west-1a"},
+		}},
+	}
+}
+
+func TestOnlySizeChanged(t *testing.T) {
+	tests := []struct {
+		name string
+		spec map[string]interface{}
+		only bool
+	}{
+		{"no change", instanceGroupSpec(1, 3, "t2.medium"), false},
+		{"min size", instanceGroupSpec(2, 3, "t2.medium"), true},
+		{"min and max size", instanceGroupSpec(2, 5, "t2.medium"), true},
+		{"machine type", instanceGroupSpec(1, 3, "m5.large"), false},
+		{"max size and machine type", instanceGroupSpec(1, 5, "m5.large"), false},
+	}
+	for _, test := range tests {
+		only := false
+		resource := &schema.Resource{
+			Schema: map[string]*schema.Schema{"spec": schemaInstanceGroupSpec()},
+			Update: func(d *schema.ResourceData, m interface{}) error {
+				only = onlySizeChanged(d)
+				return nil
+			},
+		}
+		d := schema.TestResourceDataRaw(t, resource.Schema, instanceGroupSpec(1, 3, "t2.medium"))
+		d.SetId("test.example.com/nodes")
+		state := d.State()
+
+		c, err := config.NewRawConfig(test.spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		diff, err := resource.Diff(state, terraform.NewResourceConfig(c), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff != nil {
+			if _, err := resource.Apply(state, diff, nil); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if only != test.only {
+			t.Errorf("%s: expected only size changed %v, got %v", test.name, test.only, only)
+		}
+	}
+}
@@ -0,0 +1,324 @@
+package kops
+
+import (
+	"encoding/base64"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/autoscaling"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/hashicorp/terraform/helper/schema"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/client/simple"
+	"k8s.io/kops/pkg/cloudinstances"
+	"k8s.io/kops/pkg/model"
+	clustervalidation "k8s.io/kops/pkg/validation"
+	"k8s.io/kops/upup/pkg/fi"
+	"k8s.io/kops/upup/pkg/fi/cloudup"
+	"k8s.io/kops/upup/pkg/fi/cloudup/awsup"
+)
+
+// the defaults of kops rolling-update cluster
+const (
+	rollingUpdateMasterInterval  = 5 * time.Minute
+	rollingUpdateNodeInterval    = 4 * time.Minute
+	rollingUpdateBastionInterval = 5 * time.Minute
+	rollingUpdatePostDrainDelay  = 90 * time.Second
+
+	rollingUpdateValidationInterval = 30 * time.Second
+)
+
+// rollInstanceGroupImage replaces the instances of an AWS instance group with instances of its new image.
+// The provider doesn't update the cloud resources of the cluster, so the launch configuration of the autoscaling
+// group is copied with the new image first, in the format kops names its launch configurations. The instances
+// are then replaced one by one like kops rolling-update cluster --instance-group does, draining their nodes and
+// validating the cluster in between, until the deadline of the update.
+// The copied launch configuration is created outside of kops and not tracked by the provider, kops update cluster
+// only recognizes it by its name: it takes the copy for its newest launch configuration, replaces it when it differs
+// from the kops model and deletes it like its own old launch configurations.
+func rollInstanceGroupImage(clientset simple.Clientset, cluster *kops.Cluster, instanceGroup *kops.InstanceGroup, m interface{}, deadline time.Time) error {
+	if kops.CloudProviderID(cluster.Spec.CloudProvider) != kops.CloudProviderAWS {
+		log.Printf("[WARN] Rolling instance groups on image changes is only supported on AWS, the instances of %s are replaced by kops rolling-update cluster only", instanceGroup.Name)
+		return nil
+	}
+
+	cloud, err := cloudup.BuildCloud(cluster)
+	if err != nil {
+		return err
+	}
+	launched, err := launchConfigurationWithImage(cloud.(awsup.AWSCloud), cluster, instanceGroup)
+	if err != nil || !launched {
+		return err
+	}
+
+	client, err := newKubernetesClient(cluster.Name, m)
+	if err != nil {
+		return err
+	}
+	nodes, err := client.CoreV1().Nodes().List(metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("error listing nodes of cluster %s: %v", cluster.Name, err)
+	}
+	instanceGroupList, err := clientset.InstanceGroupsFor(cluster).List(metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	groups, err := cloud.GetCloudGroups(cluster, []*kops.InstanceGroup{instanceGroup}, false, nodes.Items)
+	if err != nil {
+		return err
+	}
+	group, ok := groups[instanceGroup.Name]
+	if !ok {
+		return nil
+	}
+
+	interval := rollingUpdateNodeInterval
+	switch instanceGroup.Spec.Role {
+	case kops.InstanceGroupRoleMaster:
+		interval = rollingUpdateMasterInterval
+	case kops.InstanceGroupRoleBastion:
+		interval = rollingUpdateBastionInterval
+	}
+
+	for i, member := range group.NeedUpdate {
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timeout while rolling instance group %s, instances %s still run the previous image", instanceGroup.Name, memberIDs(group.NeedUpdate[i:]))
+		}
+
+		if member.Node != nil && instanceGroup.Spec.Role != kops.InstanceGroupRoleBastion {
+			log.Printf("[INFO] Draining node %s of instance %s", member.Node.Name, member.ID)
+			if err := cordonNode(client, member.Node.Name); err != nil {
+				return err
+			}
+			if err := drainNode(client, member.Node.Name, deadline); err != nil {
+				return err
+			}
+			sleepUntil(rollingUpdatePostDrainDelay, deadline)
+		}
+
+		log.Printf("[INFO] Replacing instance %s of instance group %s", member.ID, instanceGroup.Name)
+		if err := cloud.DeleteInstance(member); err != nil {
+			return fmt.Errorf("error deleting instance %s of instance group %s: %v", member.ID, instanceGroup.Name, err)
+		}
+		sleepUntil(interval, deadline)
+
+		if instanceGroup.Spec.Role != kops.InstanceGroupRoleBastion {
+			if err := waitForClusterValidation(cluster, instanceGroupList, client, deadline); err != nil {
+				return fmt.Errorf("cluster not healthy after replacing instance %s, stopping the rolling update of instance group %s: %v", member.ID, instanceGroup.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// waitForClusterValidation waits for the kops cluster validation to pass, until the deadline
+func waitForClusterValidation(cluster *kops.Cluster, instanceGroupList *kops.InstanceGroupList, client kubernetes.Interface, deadline time.Time) error {
+	for {
+		result, err := clustervalidation.ValidateCluster(cluster, instanceGroupList, client)
+		if err == nil && len(result.Failures) == 0 {
+			return nil
+		}
+		if err == nil {
+			err = fmt.Errorf("%s", result.Failures[0].Message)
+		}
+		if time.Now().Add(rollingUpdateValidationInterval).After(deadline) {
+			return fmt.Errorf("cluster did not validate before the timeout: %v", err)
+		}
+		log.Printf("[DEBUG] Cluster %s did not validate yet: %v", cluster.Name, err)
+		time.Sleep(rollingUpdateValidationInterval)
+	}
+}
+
+func sleepUntil(duration time.Duration, deadline time.Time) {
+	if remaining := time.Until(deadline); remaining < duration {
+		duration = remaining
+	}
+	if duration > 0 {
+		time.Sleep(duration)
+	}
+}
+
+func memberIDs(members []*cloudinstances.CloudInstanceGroupMember) string {
+	var ids []string
+	for _, member := range members {
+		ids = append(ids, member.ID)
+	}
+	return strings.Join(ids, ", ")
+}
+
+// setNeedsRollingUpdate sets needs_rolling_update from the cloud resources of the instance groups, it is left
+// unchanged when they can't be read so refreshing clusters doesn't depend on access to the cloud
+func setNeedsRollingUpdate(d *schema.ResourceData, cluster *kops.Cluster, instanceGroups []*kops.InstanceGroup, m interface{}) error {
+	needed, err := needsRollingUpdate(cluster, instanceGroups, m)
+	if err != nil {
+		log.Printf("[WARN] Unable to tell whether cluster %s needs a rolling update: %v", cluster.Name, err)
+		return nil
+	}
+	return d.Set("needs_rolling_update", needed)
+}
+
+// needsRollingUpdate reports whether a rolling update would replace instances of the instance groups of an AWS cluster:
+// instances running a previous launch configuration, or launch configurations running another image or Kubernetes
+// version than the spec, the provider updates the spec without updating the cloud resources of the cluster.
+func needsRollingUpdate(cluster *kops.Cluster, instanceGroups []*kops.InstanceGroup, m interface{}) (bool, error) {
+	if kops.CloudProviderID(cluster.Spec.CloudProvider) != kops.CloudProviderAWS || len(instanceGroups) == 0 {
+		return false, nil
+	}
+
+	if err := configureAWSCloud(cluster, m); err != nil {
+		return false, err
+	}
+	cloud, err := cloudup.BuildCloud(cluster)
+	if err != nil {
+		return false, err
+	}
+	groups, err := cloud.GetCloudGroups(cluster, instanceGroups, false, nil)
+	if err != nil {
+		return false, err
+	}
+	for _, group := range groups {
+		if len(group.NeedUpdate) > 0 {
+			return true, nil
+		}
+		outdated, err := launchConfigurationOutdated(cloud.(awsup.AWSCloud), cluster, group)
+		if err != nil || outdated {
+			return outdated, err
+		}
+	}
+	return false, nil
+}
+
+// launchConfigurationOutdated reports whether the launch configuration of an autoscaling group runs another image than
+// its instance group or another Kubernetes version than the cluster
+func launchConfigurationOutdated(cloud awsup.AWSCloud, cluster *kops.Cluster, group *cloudinstances.CloudInstanceGroup) (bool, error) {
+	asg, ok := group.Raw.(*autoscaling.Group)
+	if !ok || asg.LaunchConfigurationName == nil {
+		return false, nil
+	}
+
+	configurations, err := cloud.Autoscaling().DescribeLaunchConfigurations(&autoscaling.DescribeLaunchConfigurationsInput{
+		LaunchConfigurationNames: []*string{asg.LaunchConfigurationName},
+	})
+	if err != nil {
+		return false, fmt.Errorf("error describing launch configuration of autoscaling group %s: %v", group.HumanName, err)
+	}
+	if len(configurations.LaunchConfigurations) == 0 {
+		return false, nil
+	}
+	current := configurations.LaunchConfigurations[0]
+
+	if userData, err := base64.StdEncoding.DecodeString(aws.StringValue(current.UserData)); err == nil && !userDataRunsVersion(string(userData), cluster.Spec.KubernetesVersion) {
+		return true, nil
+	}
+
+	if group.InstanceGroup.Spec.Image == "" {
+		return false, nil
+	}
+	image, err := cloud.ResolveImage(group.InstanceGroup.Spec.Image)
+	if err != nil {
+		return false, fmt.Errorf("error resolving image %q of instance group %s: %v", group.InstanceGroup.Spec.Image, group.InstanceGroup.Name, err)
+	}
+	return aws.StringValue(current.ImageId) != aws.StringValue(image.ImageId), nil
+}
+
+// userDataRunsVersion reports whether the user data kops renders for a launch configuration installs the given
+// Kubernetes version, it names the version in the tags of the component images and the paths of the release assets.
+// User data not rendered by nodeup, like that of bastions, runs any version.
+func userDataRunsVersion(userData, version string) bool {
+	version = strings.TrimPrefix(version, "v")
+	if version == "" || !strings.Contains(userData, "__EOF_KUBE_ENV") {
+		return true
+	}
+	return strings.Contains(userData, ":v"+version+"\n") || strings.Contains(userData, "/v"+version+"/")
+}
+
+// launchConfigurationWithImage points the autoscaling group of an instance group to a copy of its launch configuration
+// using the image of the instance group, it reports false when the autoscaling group doesn't exist yet
+func launchConfigurationWithImage(cloud awsup.AWSCloud, cluster *kops.Cluster, instanceGroup *kops.InstanceGroup) (bool, error) {
+	name := (&model.KopsModelContext{Cluster: cluster}).AutoscalingGroupName(instanceGroup)
+	groups, err := cloud.Autoscaling().DescribeAutoScalingGroups(&autoscaling.DescribeAutoScalingGroupsInput{
+		AutoScalingGroupNames: []*string{aws.String(name)},
+	})
+	if err != nil {
+		return false, fmt.Errorf("error describing autoscaling group %s: %v", name, err)
+	}
+	if len(groups.AutoScalingGroups) == 0 {
+		log.Printf("[WARN] Autoscaling group %s doesn't exist yet, it is created with the new image on the next cluster update", name)
+		return false, nil
+	}
+
+	configurations, err := cloud.Autoscaling().DescribeLaunchConfigurations(&autoscaling.DescribeLaunchConfigurationsInput{
+		LaunchConfigurationNames: []*string{groups.AutoScalingGroups[0].LaunchConfigurationName},
+	})
+	if err != nil {
+		return false, fmt.Errorf("error describing launch configuration of autoscaling group %s: %v", name, err)
+	}
+	if len(configurations.LaunchConfigurations) == 0 {
+		return false, fmt.Errorf("launch configuration of autoscaling group %s not found", name)
+	}
+	current := configurations.LaunchConfigurations[0]
+
+	image, err := cloud.ResolveImage(instanceGroup.Spec.Image)
+	if err != nil {
+		return false, fmt.Errorf("error resolving image %q of instance group %s: %v", instanceGroup.Spec.Image, instanceGroup.Name, err)
+	}
+	if aws.StringValue(current.ImageId) == aws.StringValue(image.ImageId) {
+		return true, nil
+	}
+	previous, err := cloud.ResolveImage(aws.StringValue(current.ImageId))
+	if err != nil {
+		return false, fmt.Errorf("error resolving image %q of autoscaling group %s: %v", aws.StringValue(current.ImageId), name, err)
+	}
+
+	launchConfigurationName := name + "-" + fi.BuildTimestampString()
+	_, err = cloud.Autoscaling().CreateLaunchConfiguration(&autoscaling.CreateLaunchConfigurationInput{
+		LaunchConfigurationName:  aws.String(launchConfigurationName),
+		ImageId:                  image.ImageId,
+		InstanceType:             current.InstanceType,
+		KeyName:                  optionalAWSString(current.KeyName),
+		SecurityGroups:           current.SecurityGroups,
+		UserData:                 optionalAWSString(current.UserData),
+		IamInstanceProfile:       optionalAWSString(current.IamInstanceProfile),
+		BlockDeviceMappings:      withRootDevice(current.BlockDeviceMappings, previous, image),
+		EbsOptimized:             current.EbsOptimized,
+		AssociatePublicIpAddress: current.AssociatePublicIpAddress,
+		InstanceMonitoring:       current.InstanceMonitoring,
+		PlacementTenancy:         optionalAWSString(current.PlacementTenancy),
+		SpotPrice:                optionalAWSString(current.SpotPrice),
+	})
+	if err != nil {
+		return false, fmt.Errorf("error creating launch configuration %s: %v", launchConfigurationName, err)
+	}
+
+	_, err = cloud.Autoscaling().UpdateAutoScalingGroup(&autoscaling.UpdateAutoScalingGroupInput{
+		AutoScalingGroupName:    aws.String(name),
+		LaunchConfigurationName: aws.String(launchConfigurationName),
+	})
+	if err != nil {
+		return false, fmt.Errorf("error updating launch configuration of autoscaling group %s: %v", name, err)
+	}
+	return true, nil
+}
+
+// withRootDevice moves the root volume mapping of the previous image to the root device of the new image
+func withRootDevice(mappings []*autoscaling.BlockDeviceMapping, previous, image *ec2.Image) []*autoscaling.BlockDeviceMapping {
+	for _, mapping := range mappings {
+		if aws.StringValue(mapping.DeviceName) == aws.StringValue(previous.RootDeviceName) {
+			mapping.DeviceName = image.RootDeviceName
+		}
+	}
+	return mappings
+}
+
+// optionalAWSString drops the empty strings the describe calls return for unset values, the create calls reject them
+func optionalAWSString(value *string) *string {
+	if aws.StringValue(value) == "" {
+		return nil
+	}
+	return value
+}
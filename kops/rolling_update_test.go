@@ -0,0 +1,38 @@
+package kops
+
+import (
+	"testing"
+)
+
+func TestUserDataRunsVersion(t *testing.T) {
+	const nodeup = `#!/bin/bash
+cat > cluster_spec.yaml << '__EOF_CLUSTER_SPEC'
+kubeProxy:
+  image: k8s.gcr.io/kube-proxy:v1.10.3
+__EOF_CLUSTER_SPEC
+
+cat > kube_env.yaml << '__EOF_KUBE_ENV'
+Assets:
+- https://storage.googleapis.com/kubernetes-release/release/v1.10.3/bin/linux/amd64/kubelet
+__EOF_KUBE_ENV
+`
+	tests := []struct {
+		name     string
+		userData string
+		version  string
+		runs     bool
+	}{
+		{"same version", nodeup, "1.10.3", true},
+		{"same version with prefix", nodeup, "v1.10.3", true},
+		{"upgraded version", nodeup, "1.10.4", false},
+		{"version with the same prefix", nodeup, "1.10.30", false},
+		{"version of another minor", nodeup, "1.11.3", false},
+		{"no version", nodeup, "", true},
+		{"bastion", "", "1.10.4", true},
+	}
+	for _, test := range tests {
+		if runs := userDataRunsVersion(test.userData, test.version); runs != test.runs {
+			t.Errorf("%s: userDataRunsVersion(%q) = %t, want %t", test.name, test.version, runs, test.runs)
+		}
+	}
+}
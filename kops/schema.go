@@ -1,6 +1,10 @@
 package kops
 
 import (
+	"fmt"
+	"strconv"
+	"time"
+
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/helper/validation"
 )
@@ -23,6 +27,15 @@ func schemaMetadata() *schema.Schema {
 	}
 }
 
+func schemaStateStore() *schema.Schema {
+	return &schema.Schema{
+		Type:         schema.TypeString,
+		Optional:     true,
+		ForceNew:     true,
+		ValidateFunc: validateStateStore,
+	}
+}
+
 func schemaStringOptional() *schema.Schema {
 	return &schema.Schema{
 		Type:     schema.TypeString,
@@ -60,6 +73,21 @@ func schemaStringComputed() *schema.Schema {
 	}
 }
 
+func schemaStringMapOptionalComputed() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeMap,
+		Optional: true,
+		Computed: true,
+	}
+}
+
+func schemaStringMapComputed() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeMap,
+		Computed: true,
+	}
+}
+
 func schemaCIDRStringRequired() *schema.Schema {
 	return &schema.Schema{
 		Type:         schema.TypeString,
@@ -76,6 +104,15 @@ func schemaCIDRStringOptional() *schema.Schema {
 	}
 }
 
+func schemaCIDRStringOptionalComputed() *schema.Schema {
+	return &schema.Schema{
+		Type:         schema.TypeString,
+		Optional:     true,
+		Computed:     true,
+		ValidateFunc: validation.CIDRNetwork(1, 32),
+	}
+}
+
 func schemaStringInSliceRequired(slice []string) *schema.Schema {
 	return &schema.Schema{
 		Type:         schema.TypeString,
@@ -92,6 +129,15 @@ func schemaStringInSliceOptional(slice []string) *schema.Schema {
 	}
 }
 
+func schemaStringInSliceOptionalComputed(slice []string) *schema.Schema {
+	return &schema.Schema{
+		Type:         schema.TypeString,
+		Optional:     true,
+		Computed:     true,
+		ValidateFunc: validation.StringInSlice(slice, false),
+	}
+}
+
 func schemaStringInSliceOptionaDefault(slice []string, def string) *schema.Schema {
 	return &schema.Schema{
 		Type:         schema.TypeString,
@@ -108,6 +154,33 @@ func schemaIntOptional() *schema.Schema {
 	}
 }
 
+func schemaIntOptionalComputed() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeInt,
+		Optional: true,
+		Computed: true,
+	}
+}
+
+func schemaIntOptionalDeprecated(message string) *schema.Schema {
+	return &schema.Schema{
+		Type:       schema.TypeInt,
+		Optional:   true,
+		Computed:   true,
+		Deprecated: message,
+	}
+}
+
+// schemaDurationOptional is a duration, kops writes durations back in their canonical format, e.g. 60s as 1m0s
+func schemaDurationOptional() *schema.Schema {
+	return &schema.Schema{
+		Type:             schema.TypeString,
+		Optional:         true,
+		ValidateFunc:     validateDuration,
+		DiffSuppressFunc: suppressEquivalentDuration,
+	}
+}
+
 func schemaBoolOptional() *schema.Schema {
 	return &schema.Schema{
 		Type:     schema.TypeBool,
@@ -115,6 +188,101 @@ func schemaBoolOptional() *schema.Schema {
 	}
 }
 
+func schemaDurationOptionalComputed() *schema.Schema {
+	return &schema.Schema{
+		Type:             schema.TypeString,
+		Optional:         true,
+		Computed:         true,
+		ValidateFunc:     validateDuration,
+		DiffSuppressFunc: suppressEquivalentDuration,
+	}
+}
+
+func suppressEquivalentDuration(k, old, new string, d *schema.ResourceData) bool {
+	oldValue, oldErr := time.ParseDuration(old)
+	newValue, newErr := time.ParseDuration(new)
+	return oldErr == nil && newErr == nil && oldValue == newValue
+}
+
+// schemaTriStateBoolOptional is a bool that can be left unset, TypeBool stores an unset value as false.
+// Terraform decodes the bool literals of the configuration as "1" and "0".
+func schemaTriStateBoolOptional() *schema.Schema {
+	return &schema.Schema{
+		Type:             schema.TypeString,
+		Optional:         true,
+		ValidateFunc:     validateTriStateBool,
+		DiffSuppressFunc: suppressEquivalentBool,
+	}
+}
+
+func schemaTriStateBoolOptionalComputed() *schema.Schema {
+	s := schemaTriStateBoolOptional()
+	s.Computed = true
+	return s
+}
+
+// schemaTriStateIntOptional is an int that can be left unset, TypeInt stores an unset value as 0
+func schemaTriStateIntOptional() *schema.Schema {
+	return &schema.Schema{
+		Type:         schema.TypeString,
+		Optional:     true,
+		ValidateFunc: validateTriStateInt,
+	}
+}
+
+func schemaTriStateIntOptionalComputed() *schema.Schema {
+	s := schemaTriStateIntOptional()
+	s.Computed = true
+	return s
+}
+
+func validateTriStateBool(v interface{}, k string) ([]string, []error) {
+	if value := v.(string); value != "" {
+		if _, err := strconv.ParseBool(value); err != nil {
+			return nil, []error{fmt.Errorf("%q: %q is not a bool", k, value)}
+		}
+	}
+	return nil, nil
+}
+
+func validateTriStateInt(v interface{}, k string) ([]string, []error) {
+	if value := v.(string); value != "" {
+		if _, err := strconv.ParseInt(value, 10, 32); err != nil {
+			return nil, []error{fmt.Errorf("%q: %q is not an integer", k, value)}
+		}
+	}
+	return nil, nil
+}
+
+func suppressEquivalentBool(k, old, new string, d *schema.ResourceData) bool {
+	oldValue, oldErr := strconv.ParseBool(old)
+	newValue, newErr := strconv.ParseBool(new)
+	return oldErr == nil && newErr == nil && oldValue == newValue
+}
+
+func schemaBoolOptionalDefault(def bool) *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeBool,
+		Optional: true,
+		Default:  def,
+	}
+}
+
+func schemaBoolComputed() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeBool,
+		Computed: true,
+	}
+}
+
+func schemaBoolOptionalComputed() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeBool,
+		Optional: true,
+		Computed: true,
+	}
+}
+
 func schemaStringSliceRequired() *schema.Schema {
 	return &schema.Schema{
 		Type:     schema.TypeList,
@@ -131,6 +299,52 @@ func schemaStringSliceOptional() *schema.Schema {
 	}
 }
 
+func schemaStringSliceOptionalComputed() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		Computed: true,
+		Elem:     &schema.Schema{Type: schema.TypeString},
+	}
+}
+
+func schemaStringSliceComputed() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Computed: true,
+		Elem:     &schema.Schema{Type: schema.TypeString},
+	}
+}
+
+func schemaStringInSliceSliceOptional(slice []string) *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		Elem: &schema.Schema{
+			Type:         schema.TypeString,
+			ValidateFunc: validation.StringInSlice(slice, false),
+		},
+	}
+}
+
+func schemaStringSetRequired() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeSet,
+		Required: true,
+		Elem:     &schema.Schema{Type: schema.TypeString},
+		Set:      schema.HashString,
+	}
+}
+
+func schemaStringSetOptional() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeSet,
+		Optional: true,
+		Elem:     &schema.Schema{Type: schema.TypeString},
+		Set:      schema.HashString,
+	}
+}
+
 func schemaStringMap() *schema.Schema {
 	return &schema.Schema{
 		Type:     schema.TypeMap,
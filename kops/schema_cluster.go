@@ -11,39 +11,231 @@ func schemaClusterSpec() *schema.Schema {
 		MaxItems: 1,
 		Elem: &schema.Resource{
 			Schema: map[string]*schema.Schema{
+				"api":                     schemaAPIAccess(),
+				"assets":                  schemaAssets(),
+				"authentication":          schemaAuthentication(),
+				"authorization":           schemaAuthorization(),
 				"channel":                 schemaStringOptionalComputed(),
+				"cloud_config":            schemaCloudConfiguration(),
+				"cloud_labels":            schemaStringMap(),
 				"cloud_provider":          schemaStringRequired(),
 				"cluster_dnsdomain":       schemaStringOptionalComputed(),
-				"config_base":             schemaStringComputed(),
+				"config_base":             schemaStringOptionalComputed(),
 				"config_store":            schemaStringOptionalComputed(),
 				"dnszone":                 schemaStringOptionalComputed(),
+				"docker":                  schemaDocker(),
+				"encryption_config":       schemaBoolOptional(),
+				"file_asset":              schemaFileAsset(),
+				"hook":                    schemaHook(),
+				"iam":                     schemaIAM(),
 				"key_store":               schemaStringOptionalComputed(),
 				"kube_api_server":         schemaKubeApiServer(),
+				"kube_controller_manager": schemaKubeControllerManager(),
 				"kube_dns":                schemaKubeDNS(),
 				"kube_proxy":              schemaKubeProxy(),
 				"kube_scheduler":          schemaKubeScheduler(),
+				"kubelet":                 schemaKubelet(),
 				"kubernetes_version":      schemaStringRequired(),
 				"master_internal_name":    schemaStringOptionalComputed(),
+				"master_kubelet":          schemaKubelet(),
 				"master_public_name":      schemaStringOptionalComputed(),
 				"project":                 schemaStringOptional(),
 				"secret_store":            schemaStringOptionalComputed(),
 				"service_cluster_iprange": schemaStringOptionalComputed(),
 				"sshkey_name":             schemaStringOptional(),
 				"network_id":              schemaStringOptional(),
-				"network_cidr":            schemaCIDRStringOptional(),
-				"non_masquerade_cidr":     schemaCIDRStringOptional(),
+				"network_cidr":            schemaCIDRStringOptionalComputed(),
+				"non_masquerade_cidr":     schemaCIDRStringOptionalComputed(),
 				"ssh_access":              schemaStringSliceOptional(),
 				"kubernetes_api_access":   schemaStringSliceOptional(),
+				"addons":                  schemaStringSliceOptional(),
 				"additional_policies":     schemaStringMap(),
+				"additional_sans":         schemaStringSliceOptional(),
 				"subnet":                  schemaClusterSubnet(),
 				"topology":                schemaClusterTopology(),
+				"update_policy":           schemaStringInSliceOptional([]string{"external"}),
 				"etcd_cluster":            schemaClusterEtcdCluster(),
+				"external_dns":            schemaExternalDNS(),
 				"networking":              schemaNetworkingSpec(),
 			},
 		},
 	}
 }
 
+func schemaClusterComputedSpec() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Computed: true,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"kubernetes_version":       schemaStringComputed(),
+				"config_base":              schemaStringComputed(),
+				"master_public_name":       schemaStringComputed(),
+				"master_internal_name":     schemaStringComputed(),
+				"network_cidr":             schemaStringComputed(),
+				"non_masquerade_cidr":      schemaStringComputed(),
+				"service_cluster_ip_range": schemaStringComputed(),
+				"pod_cidr":                 schemaStringComputed(),
+				"dns_server_ip":            schemaStringComputed(),
+				"kube_api_server_image":    schemaStringComputed(),
+				"etcd_versions":            schemaStringMapComputed(),
+				"etcd_images":              schemaStringMapComputed(),
+			},
+		},
+	}
+}
+
+func schemaAPIAccess() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		Computed: true,
+		MaxItems: 1,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"dns": schemaBoolOptional(),
+				"load_balancer": {
+					Type:     schema.TypeList,
+					Optional: true,
+					MaxItems: 1,
+					Elem: &schema.Resource{
+						Schema: map[string]*schema.Schema{
+							"type":                       schemaStringInSliceRequired([]string{"Public", "Internal"}),
+							"idle_timeout_seconds":       schemaIntOptional(),
+							"additional_security_groups": schemaStringSetOptional(),
+							"use_for_internal_api":       schemaBoolOptional(),
+							"ssl_certificate":            schemaStringOptional(),
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func schemaAssets() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		Computed: true,
+		MaxItems: 1,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"container_registry": schemaStringOptionalComputed(),
+				"container_proxy":    schemaStringOptionalComputed(),
+				"file_repository":    schemaStringOptionalComputed(),
+			},
+		},
+	}
+}
+
+func schemaAuthentication() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		MaxItems: 1,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"type": schemaStringInSliceRequired([]string{"aws", "kopeio"}),
+			},
+		},
+	}
+}
+
+func schemaAuthorization() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		MaxItems: 1,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"type": schemaStringInSliceRequired([]string{"RBAC", "AlwaysAllow"}),
+			},
+		},
+	}
+}
+
+func schemaCloudConfiguration() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		Computed: true,
+		MaxItems: 1,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"multizone":                      schemaBoolOptionalComputed(),
+				"node_tags":                      schemaStringOptionalComputed(),
+				"node_instance_prefix":           schemaStringOptionalComputed(),
+				"disable_security_group_ingress": schemaBoolOptional(),
+				"elb_security_group":             schemaStringOptional(),
+			},
+		},
+	}
+}
+
+func schemaDocker() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		Computed: true,
+		MaxItems: 1,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"authorization_plugins": schemaStringSliceOptional(),
+				"bridge":                schemaStringOptionalComputed(),
+				"bridge_ip":             schemaStringOptionalComputed(),
+				"data_root":             schemaStringOptionalComputed(),
+				"default_ulimit":        schemaStringSliceOptional(),
+				"exec_root":             schemaStringOptionalComputed(),
+				"hosts":                 schemaStringSliceOptional(),
+				"ip_masq":               schemaBoolOptionalComputed(),
+				"ip_tables":             schemaBoolOptionalComputed(),
+				"insecure_registry":     schemaStringOptionalComputed(),
+				"live_restore":          schemaBoolOptionalComputed(),
+				"log_driver":            schemaStringOptionalComputed(),
+				"log_level":             schemaStringOptionalComputed(),
+				"log_opt":               schemaStringSliceOptionalComputed(),
+				"mtu":                   schemaIntOptional(),
+				"registry_mirrors":      schemaStringSliceOptional(),
+				"storage":               schemaStringOptionalComputed(),
+				"storage_opts":          schemaStringSliceOptional(),
+				"user_namespace_remap":  schemaStringOptional(),
+				"version":               schemaStringOptionalComputed(),
+			},
+		},
+	}
+}
+
+func schemaExternalDNS() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		MaxItems: 1,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"disable":         schemaBoolOptional(),
+				"watch_ingress":   schemaBoolOptional(),
+				"watch_namespace": schemaStringOptional(),
+			},
+		},
+	}
+}
+
+func schemaIAM() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		Computed: true,
+		MaxItems: 1,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"legacy":                   schemaBoolOptional(),
+				"allow_container_registry": schemaBoolOptional(),
+			},
+		},
+	}
+}
+
 func schemaKubeApiServer() *schema.Schema {
 	return &schema.Schema{
 		Type:     schema.TypeList,
@@ -53,44 +245,46 @@ func schemaKubeApiServer() *schema.Schema {
 		Elem: &schema.Resource{
 			Schema: map[string]*schema.Schema{
 				"address":                                  schemaStringOptionalComputed(),
-				"api_server_count":                         schemaIntOptional(),
+				"admission_control":                        schemaStringSliceOptionalComputed(),
+				"api_server_count":                         schemaIntOptionalComputed(),
 				"audit_log_format":                         schemaStringOptionalComputed(),
 				"audit_log_max_age":                        schemaIntOptional(),
 				"audit_log_max_backups":                    schemaIntOptional(),
 				"audit_log_max_size":                       schemaIntOptional(),
 				"audit_log_path":                           schemaStringOptionalComputed(),
 				"audit_policy_file":                        schemaStringOptionalComputed(),
-				"authentication_token_webhook_cache_ttl":   schemaStringOptionalComputed(),
+				"authentication_token_webhook_cache_ttl":   schemaDurationOptionalComputed(),
 				"authentication_token_webhook_config_file": schemaStringOptionalComputed(),
 				"authorization_mode":                       schemaStringOptionalComputed(),
 				"authorization_rbac_super_user":            schemaStringOptionalComputed(),
-				"allow_privileged":                         schemaBoolOptional(),
-				"anonymous_auth":                           schemaBoolOptional(),
+				"allow_privileged":                         schemaBoolOptionalComputed(),
+				"anonymous_auth":                           schemaBoolOptionalComputed(),
 				"basic_auth_file":                          schemaStringOptionalComputed(),
 				"bind_address":                             schemaStringOptionalComputed(),
 				"client_ca_file":                           schemaStringOptionalComputed(),
 				"cloud_provider":                           schemaStringOptionalComputed(),
 				"disable_admission_plugins":                schemaStringSliceOptional(),
-				"enable_admission_plugins":                 schemaStringSliceOptional(),
+				"enable_admission_plugins":                 schemaStringSliceOptionalComputed(),
 				"enable_aggregator_routing":                schemaBoolOptional(),
 				"enable_bootstrap_auth_token":              schemaBoolOptional(),
 				"etcd_ca_file":                             schemaStringOptionalComputed(),
 				"etcd_cert_file":                           schemaStringOptionalComputed(),
 				"etcd_key_file":                            schemaStringOptionalComputed(),
-				"etcd_quorum_read":                         schemaBoolOptional(),
-				"etcd_servers":                             schemaStringSliceOptional(),
-				"etcd_servers_overrides":                   schemaStringSliceOptional(),
+				"etcd_quorum_read":                         schemaBoolOptionalComputed(),
+				"etcd_servers":                             schemaStringSliceOptionalComputed(),
+				"etcd_servers_overrides":                   schemaStringSliceOptionalComputed(),
 				"experimental_encryption_provider_config":  schemaStringOptionalComputed(),
 				"feature_gates":                            schemaStringMap(),
 				"insecure_bind_address":                    schemaStringOptionalComputed(),
-				"insecure_port":                            schemaIntOptional(),
+				"insecure_port":                            schemaIntOptionalComputed(),
 				"image":                                    schemaStringOptionalComputed(),
 				"kubelet_client_certificate":               schemaStringOptionalComputed(),
 				"kubelet_client_key":                       schemaStringOptionalComputed(),
-				"kubelet_preferred_address_types":          schemaStringSliceOptional(),
-				"log_level":                                schemaIntOptional(),
+				"kubelet_preferred_address_types":          schemaStringSliceOptionalComputed(),
+				"log_level":                                schemaIntOptionalComputed(),
 				"max_requests_inflight":                    schemaIntOptional(),
-				"mix_request_timeout":                      schemaIntOptional(),
+				"min_request_timeout":                      schemaIntOptionalComputed(),
+				"mix_request_timeout":                      schemaIntOptionalDeprecated("use min_request_timeout instead"),
 				"oidc_ca_file":                             schemaStringOptionalComputed(),
 				"oidc_client_id":                           schemaStringOptionalComputed(),
 				"oidc_groups_claim":                        schemaStringOptionalComputed(),
@@ -100,13 +294,13 @@ func schemaKubeApiServer() *schema.Schema {
 				"oidc_username_prefix":                     schemaStringOptionalComputed(),
 				"proxy_client_cert_file":                   schemaStringOptionalComputed(),
 				"proxy_client_key_file":                    schemaStringOptionalComputed(),
-				"requestheader_allowed_names":              schemaStringSliceOptional(),
+				"requestheader_allowed_names":              schemaStringSliceOptionalComputed(),
 				"requestheader_client_ca_file":             schemaStringOptionalComputed(),
-				"requestheader_extra_header_prefixes":      schemaStringSliceOptional(),
-				"requestheader_group_headers":              schemaStringSliceOptional(),
-				"requestheader_username_headers":           schemaStringSliceOptional(),
+				"requestheader_extra_header_prefixes":      schemaStringSliceOptionalComputed(),
+				"requestheader_group_headers":              schemaStringSliceOptionalComputed(),
+				"requestheader_username_headers":           schemaStringSliceOptionalComputed(),
 				"runtime_config":                           schemaStringMap(),
-				"secure_port":                              schemaIntOptional(),
+				"secure_port":                              schemaIntOptionalComputed(),
 				"service_cluster_ip_range":                 schemaStringOptionalComputed(),
 				"service_node_port_range":                  schemaStringOptionalComputed(),
 				"storage_backend":                          schemaStringOptionalComputed(),
@@ -126,12 +320,12 @@ func schemaKubeDNS() *schema.Schema {
 		MaxItems: 1,
 		Elem: &schema.Resource{
 			Schema: map[string]*schema.Schema{
-				"cache_max_concurrent": schemaIntOptional(),
-				"cache_max_size":       schemaIntOptional(),
+				"cache_max_concurrent": schemaIntOptionalComputed(),
+				"cache_max_size":       schemaIntOptionalComputed(),
 				"domain":               schemaStringOptionalComputed(),
 				"image":                schemaStringOptionalComputed(),
-				"provider":             schemaStringOptionalComputed(),
-				"replicas":             schemaIntOptional(),
+				"provider":             schemaStringInSliceOptionalComputed([]string{"KubeDNS", "CoreDNS"}),
+				"replicas":             schemaIntOptionalComputed(),
 				"server_ip":            schemaStringOptionalComputed(),
 				"stub_domains":         schemaStringMap(),
 				"upstream_nameservers": schemaStringSliceOptional(),
@@ -154,15 +348,51 @@ func schemaKubeProxy() *schema.Schema {
 				"cluster_cidr":           schemaStringOptionalComputed(),
 				"cpu_limit":              schemaStringOptionalComputed(),
 				"cpu_request":            schemaStringOptionalComputed(),
-				"enabled":                schemaBoolOptional(),
+				"enabled":                schemaBoolOptionalDefault(true),
 				"feature_gates":          schemaStringMap(),
 				"hostname_override":      schemaStringOptionalComputed(),
 				"image":                  schemaStringOptionalComputed(),
-				"log_level":              schemaIntOptional(),
+				"log_level":              schemaIntOptionalComputed(),
 				"master":                 schemaStringOptionalComputed(),
 				"memory_limit":           schemaStringOptionalComputed(),
 				"memory_request":         schemaStringOptionalComputed(),
-				"proxy_mode":             schemaStringOptionalComputed(),
+				"proxy_mode":             schemaStringInSliceOptionalComputed([]string{"userspace", "iptables", "ipvs"}),
+			},
+		},
+	}
+}
+
+func schemaKubeControllerManager() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		Computed: true,
+		MaxItems: 1,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"allocate_node_cidrs":                        schemaBoolOptionalComputed(),
+				"attach_detach_reconcile_sync_period":        schemaDurationOptionalComputed(),
+				"cidr_allocator_type":                        schemaStringOptionalComputed(),
+				"cloud_provider":                             schemaStringOptionalComputed(),
+				"cluster_cidr":                               schemaStringOptionalComputed(),
+				"cluster_name":                               schemaStringOptionalComputed(),
+				"configure_cloud_routes":                     schemaBoolOptionalComputed(),
+				"feature_gates":                              schemaStringMap(),
+				"horizontal_pod_autoscaler_sync_period":      schemaDurationOptional(),
+				"horizontal_pod_autoscaler_downscale_delay":  schemaDurationOptional(),
+				"horizontal_pod_autoscaler_upscale_delay":    schemaDurationOptional(),
+				"horizontal_pod_autoscaler_use_rest_clients": schemaBoolOptionalComputed(),
+				"image":                            schemaStringOptionalComputed(),
+				"leader_election":                  schemaLeaderElection(),
+				"log_level":                        schemaIntOptionalComputed(),
+				"master":                           schemaStringOptionalComputed(),
+				"node_monitor_grace_period":        schemaDurationOptional(),
+				"node_monitor_period":              schemaDurationOptional(),
+				"pod_eviction_timeout":             schemaDurationOptional(),
+				"root_ca_file":                     schemaStringOptionalComputed(),
+				"service_account_private_key_file": schemaStringOptionalComputed(),
+				"terminated_pod_gc_threshold":      schemaIntOptional(),
+				"use_service_account_credentials":  schemaBoolOptionalComputed(),
 			},
 		},
 	}
@@ -179,7 +409,7 @@ func schemaKubeScheduler() *schema.Schema {
 				"feature_gates":         schemaStringMap(),
 				"image":                 schemaStringOptionalComputed(),
 				"leader_election":       schemaLeaderElection(),
-				"log_level":             schemaIntOptional(),
+				"log_level":             schemaIntOptionalComputed(),
 				"master":                schemaStringOptionalComputed(),
 				"use_policy_config_map": schemaBoolOptional(),
 			},
@@ -195,7 +425,7 @@ func schemaLeaderElection() *schema.Schema {
 		MaxItems: 1,
 		Elem: &schema.Resource{
 			Schema: map[string]*schema.Schema{
-				"leader_elect": schemaBoolOptional(),
+				"leader_elect": schemaBoolOptionalComputed(),
 			},
 		},
 	}
@@ -208,10 +438,13 @@ func schemaClusterSubnet() *schema.Schema {
 		Computed: true,
 		Elem: &schema.Resource{
 			Schema: map[string]*schema.Schema{
-				"name": schemaStringRequired(),
-				"zone": schemaStringRequired(),
-				"cidr": schemaCIDRStringRequired(),
-				"type": schemaStringInSliceRequired([]string{"Public", "Private", "Utility"}),
+				"name":      schemaStringRequired(),
+				"zone":      schemaStringRequired(),
+				"cidr":      schemaCIDRStringOptionalComputed(),
+				"type":      schemaStringInSliceRequired([]string{"Public", "Private", "Utility"}),
+				"id":        schemaStringOptional(),
+				"egress":    schemaStringOptional(),
+				"public_ip": schemaStringOptional(),
 			},
 		},
 	}
@@ -224,8 +457,8 @@ func schemaClusterTopology() *schema.Schema {
 		MaxItems: 1,
 		Elem: &schema.Resource{
 			Schema: map[string]*schema.Schema{
-				"masters": schemaStringOptionalDefault("public"),
-				"nodes":   schemaStringOptionalDefault("public"),
+				"masters": schemaStringInSliceOptionaDefault([]string{"public", "private"}, "public"),
+				"nodes":   schemaStringInSliceOptionaDefault([]string{"public", "private"}, "public"),
 				"bastion": {
 					Type:     schema.TypeList,
 					Optional: true,
@@ -274,12 +507,12 @@ func schemaClusterEtcdCluster() *schema.Schema {
 						},
 					},
 				},
-				"version":                 schemaStringOptional(),
-				"image":                   schemaStringOptional(),
+				"version":                 schemaStringOptionalComputed(),
+				"image":                   schemaStringOptionalComputed(),
 				"enable_etcd_tls":         schemaBoolOptional(),
 				"enable_tls_auth":         schemaBoolOptional(),
-				"leader_election_timeout": schemaIntOptional(),
-				"heartbeat_interval":      schemaIntOptional(),
+				"leader_election_timeout": schemaDurationOptional(),
+				"heartbeat_interval":      schemaDurationOptional(),
 				"backups": {
 					Type:     schema.TypeList,
 					Optional: true,
@@ -287,7 +520,7 @@ func schemaClusterEtcdCluster() *schema.Schema {
 					Elem: &schema.Resource{
 						Schema: map[string]*schema.Schema{
 							"backup_store": schemaStringOptional(),
-							"image":        schemaStringOptional(),
+							"image":        schemaStringOptionalComputed(),
 						},
 					},
 				},
@@ -312,7 +545,58 @@ func schemaNetworkingSpec() *schema.Schema {
 		Required: true,
 		Elem: &schema.Resource{
 			Schema: map[string]*schema.Schema{
-				"name": schemaStringRequired(),
+				"name":   schemaStringRequired(),
+				"cilium": schemaCiliumNetworking(),
+				"calico": schemaCalicoNetworking(),
+			},
+		},
+	}
+}
+
+func schemaCalicoNetworking() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		Computed: true,
+		MaxItems: 1,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"cross_subnet":                       schemaBoolOptional(),
+				"log_severity_screen":                schemaStringOptionalComputed(),
+				"mtu":                                schemaIntOptional(),
+				"prometheus_metrics_enabled":         schemaBoolOptional(),
+				"prometheus_metrics_port":            schemaIntOptional(),
+				"prometheus_go_metrics_enabled":      schemaBoolOptional(),
+				"prometheus_process_metrics_enabled": schemaBoolOptional(),
+			},
+		},
+	}
+}
+
+func schemaCiliumNetworking() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		Computed: true,
+		MaxItems: 1,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"version":                     schemaStringOptionalComputed(),
+				"agent_labels":                schemaStringSliceOptional(),
+				"allow_localhost":             schemaStringOptionalComputed(),
+				"debug":                       schemaBoolOptional(),
+				"device":                      schemaStringOptionalComputed(),
+				"disable_conntrack":           schemaBoolOptional(),
+				"disable_masquerade":          schemaBoolOptional(),
+				"enable_policy":               schemaStringOptionalComputed(),
+				"enable_tracing":              schemaBoolOptional(),
+				"ipv4_cluster_cidr_mask_size": schemaIntOptional(),
+				"ipv4_range":                  schemaStringOptionalComputed(),
+				"ipv4_service_range":          schemaStringOptionalComputed(),
+				"lb":                          schemaStringOptionalComputed(),
+				"prometheus_serve_addr":       schemaStringOptionalComputed(),
+				"single_cluster_route":        schemaBoolOptional(),
+				"tunnel":                      schemaStringOptionalComputed(),
 			},
 		},
 	}
@@ -16,22 +16,36 @@ func schemaInstanceGroupSpec() *schema.Schema {
 				"image":                        schemaStringOptionalComputed(),
 				"min_size":                     schemaIntOptional(),
 				"max_size":                     schemaIntOptional(),
+				"max_price":                    schemaStringOptional(),
 				"root_volume_size":             schemaIntOptional(),
 				"root_volume_type":             schemaStringOptional(),
 				"root_volume_iops":             schemaIntOptional(),
 				"root_volume_optimization":     schemaBoolOptional(),
-				"subnets":                      schemaStringSliceRequired(),
-				"zones":                        schemaStringSliceRequired(),
+				"subnets":                      schemaStringSetRequired(),
+				"zones":                        schemaStringSetRequired(),
 				"cloud_labels":                 schemaStringMap(),
 				"node_labels":                  schemaStringMap(),
-				"additional_security_groups":   schemaStringSliceOptional(),
+				"taints":                       schemaStringSetOptional(),
+				"additional_security_groups":   schemaStringSetOptional(),
 				"additional_user_data":         schemaUserData(),
-				"associate_public_ip":          schemaBoolOptional(),
+				"associate_public_ip":          schemaBoolOptionalDefault(true),
 				"detailed_instance_monitoring": schemaBoolOptional(),
 				"external_load_balancer":       schemaLoadBalancer(),
 				"file_asset":                   schemaFileAsset(),
 				"hook":                         schemaHook(),
+				"iam":                          schemaInstanceGroupIAM(),
 				"kubelet":                      schemaKubelet(),
+				"suspend_processes": schemaStringInSliceSliceOptional([]string{
+					"Launch",
+					"Terminate",
+					"HealthCheck",
+					"ReplaceUnhealthy",
+					"AZRebalance",
+					"AlarmNotification",
+					"ScheduledActions",
+					"AddToLoadBalancer",
+				}),
+				"tenancy": schemaStringInSliceOptional([]string{"default", "dedicated", "host"}),
 			},
 		},
 	}
@@ -47,71 +61,71 @@ func schemaKubelet() *schema.Schema {
 			Schema: map[string]*schema.Schema{
 				"api_servers":                            schemaStringOptionalComputed(),
 				"authorization_mode":                     schemaStringOptionalComputed(),
-				"allow_privileged":                       schemaBoolOptional(),
-				"anonymous_auth":                         schemaBoolOptional(),
-				"authentication_token_webhook":           schemaBoolOptional(),
-				"authentication_token_webhook_cache_ttl": schemaStringOptionalComputed(),
-				"babysit_daemons":                        schemaBoolOptional(),
+				"allow_privileged":                       schemaTriStateBoolOptionalComputed(),
+				"anonymous_auth":                         schemaTriStateBoolOptional(),
+				"authentication_token_webhook":           schemaTriStateBoolOptional(),
+				"authentication_token_webhook_cache_ttl": schemaDurationOptionalComputed(),
+				"babysit_daemons":                        schemaTriStateBoolOptionalComputed(),
 				"bootstrap_kubeconfig":                   schemaStringOptionalComputed(),
 				"cgroup_root":                            schemaStringOptionalComputed(),
 				"client_ca_file":                         schemaStringOptionalComputed(),
 				"cloud_provider":                         schemaStringOptionalComputed(),
 				"cluster_dns":                            schemaStringOptionalComputed(),
 				"cluster_domain":                         schemaStringOptionalComputed(),
-				"configure_cbr0":                         schemaBoolOptional(),
-				"docker_disable_shared_pid":              schemaBoolOptional(),
-				"enable_custom_metrics":                  schemaBoolOptional(),
-				"enable_debugging_handlers":              schemaBoolOptional(),
+				"configure_cbr0":                         schemaTriStateBoolOptional(),
+				"docker_disable_shared_pid":              schemaTriStateBoolOptional(),
+				"enable_custom_metrics":                  schemaTriStateBoolOptional(),
+				"enable_debugging_handlers":              schemaTriStateBoolOptionalComputed(),
 				"enforce_node_allocatable":               schemaStringOptionalComputed(),
 				"eviction_hard":                          schemaStringOptionalComputed(),
 				"eviction_max_pod_grace_period":          schemaIntOptional(),
 				"eviction_minimum_reclaim":               schemaStringOptionalComputed(),
-				"eviction_pressure_transition_period":    schemaStringOptionalComputed(),
+				"eviction_pressure_transition_period":    schemaDurationOptionalComputed(),
 				"eviction_soft":                          schemaStringOptionalComputed(),
 				"eviction_soft_grace_period":             schemaStringOptionalComputed(),
 				"experimental_allowed_unsafe_sysctls":    schemaStringSliceOptional(),
-				"fail_swap_on":                           schemaBoolOptional(),
-				"feature_gates":                          schemaStringMap(),
+				"fail_swap_on":                           schemaTriStateBoolOptional(),
+				"feature_gates":                          schemaStringMapOptionalComputed(),
 				"hairpin_mode":                           schemaStringOptionalComputed(),
 				"hostname_override":                      schemaStringOptionalComputed(),
-				"image_gc_high_threshold_percent":        schemaIntOptional(),
-				"image_gc_low_threshold_percent":         schemaIntOptional(),
-				"image_pull_progress_deadline":           schemaStringOptionalComputed(),
+				"image_gc_high_threshold_percent":        schemaTriStateIntOptional(),
+				"image_gc_low_threshold_percent":         schemaTriStateIntOptional(),
+				"image_pull_progress_deadline":           schemaDurationOptionalComputed(),
 				"kubeconfig_path":                        schemaStringOptionalComputed(),
 				"kubelet_cgroups":                        schemaStringOptionalComputed(),
 				"kube_reserved":                          schemaStringMap(),
 				"kube_reserved_cgroup":                   schemaStringOptionalComputed(),
-				"log_level":                              schemaIntOptional(),
-				"max_pods":                               schemaIntOptional(),
-				"network_plugin_mtu":                     schemaIntOptional(),
+				"log_level":                              schemaTriStateIntOptionalComputed(),
+				"max_pods":                               schemaTriStateIntOptional(),
+				"network_plugin_mtu":                     schemaTriStateIntOptionalComputed(),
 				"network_plugin_name":                    schemaStringOptionalComputed(),
 				"node_labels":                            schemaStringMap(),
-				"node_status_update_frequency":           schemaStringOptionalComputed(),
+				"node_status_update_frequency":           schemaDurationOptionalComputed(),
 				"non_masquerade_cidr":                    schemaStringOptionalComputed(),
 				"nvidia_gpus":                            schemaIntOptional(),
 				"pod_cidr":                               schemaStringOptionalComputed(),
 				"pod_infra_container_image":              schemaStringOptionalComputed(),
 				"pod_manifest_path":                      schemaStringOptionalComputed(),
-				"read_only_port":                         schemaIntOptional(),
-				"reconcile_cidr":                         schemaBoolOptional(),
-				"register_node":                          schemaBoolOptional(),
-				"register_schedulable":                   schemaBoolOptional(),
-				"require_kubeconfig":                     schemaBoolOptional(),
+				"read_only_port":                         schemaTriStateIntOptional(),
+				"reconcile_cidr":                         schemaTriStateBoolOptionalComputed(),
+				"register_node":                          schemaTriStateBoolOptional(),
+				"register_schedulable":                   schemaTriStateBoolOptionalComputed(),
+				"require_kubeconfig":                     schemaTriStateBoolOptionalComputed(),
 				"resolver_config":                        schemaStringOptionalComputed(),
 				"root_dir":                               schemaStringOptionalComputed(),
-				"runtime_request_timeout":                schemaStringOptionalComputed(),
+				"runtime_request_timeout":                schemaDurationOptionalComputed(),
 				"runtime_cgroups":                        schemaStringOptionalComputed(),
 				"seccomp_profile_root":                   schemaStringOptionalComputed(),
-				"serialize_image_pulls":                  schemaBoolOptional(),
-				"streaming_connection_idle_timeout":      schemaStringOptionalComputed(),
+				"serialize_image_pulls":                  schemaTriStateBoolOptional(),
+				"streaming_connection_idle_timeout":      schemaDurationOptionalComputed(),
 				"system_cgroups":                         schemaStringOptionalComputed(),
 				"system_reserved":                        schemaStringMap(),
 				"system_reserved_cgroup":                 schemaStringOptionalComputed(),
-				"taints":                                 schemaStringSliceOptional(),
+				"taints":                                 schemaStringSetOptional(),
 				"tls_cert_file":                          schemaStringOptionalComputed(),
 				"tls_private_key_file":                   schemaStringOptionalComputed(),
 				"volume_plugin_directory":                schemaStringOptionalComputed(),
-				"volume_stats_agg_period":                schemaStringOptionalComputed(),
+				"volume_stats_agg_period":                schemaDurationOptionalComputed(),
 			},
 		},
 	}
@@ -124,9 +138,35 @@ func schemaUserData() *schema.Schema {
 		Computed: true,
 		Elem: &schema.Resource{
 			Schema: map[string]*schema.Schema{
-				"name":    schemaStringRequired(),
-				"type":    schemaStringRequired(),
-				"content": schemaStringRequired(),
+				"name": schemaStringRequired(),
+				"type": schemaStringInSliceRequired([]string{
+					"text/x-include-once-url",
+					"text/x-include-url",
+					"text/cloud-config-archive",
+					"text/upstart-job",
+					"text/cloud-config",
+					"text/part-handler",
+					"text/x-shellscript",
+					"text/cloud-boothook",
+				}),
+				"content": {
+					Type:      schema.TypeString,
+					Required:  true,
+					Sensitive: true,
+				},
+			},
+		},
+	}
+}
+
+func schemaInstanceGroupIAM() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		MaxItems: 1,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"profile": schemaStringRequired(),
 			},
 		},
 	}
@@ -157,7 +197,7 @@ func schemaFileAsset() *schema.Schema {
 				"path":      schemaStringRequired(),
 				"content":   schemaStringRequired(),
 				"is_base64": schemaBoolOptional(),
-				"roles":     schemaStringSliceRequired(),
+				"roles":     schemaStringInSliceSliceOptional([]string{"Master", "Node", "Bastion"}),
 			},
 		},
 	}
@@ -172,10 +212,10 @@ func schemaHook() *schema.Schema {
 			Schema: map[string]*schema.Schema{
 				"name":           schemaStringRequired(),
 				"disabled":       schemaBoolOptional(),
-				"manifest":       schemaStringRequired(),
+				"manifest":       schemaStringOptional(),
 				"before":         schemaStringSliceOptional(),
 				"requires":       schemaStringSliceOptional(),
-				"roles":          schemaStringSliceRequired(),
+				"roles":          schemaStringInSliceSliceOptional([]string{"Master", "Node", "Bastion"}),
 				"exec_container": schemaExecContainer(),
 			},
 		},
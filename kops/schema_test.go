@@ -0,0 +1,64 @@
+package kops
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform/config"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func validateRaw(t *testing.T, resource map[string]*schema.Schema, raw map[string]interface{}) []error {
+	t.Helper()
+	c, err := config.NewRawConfig(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, errs := (&schema.Resource{Schema: resource}).Validate(terraform.NewResourceConfig(c))
+	return errs
+}
+
+func TestKubeletFeatureGatesConfigurable(t *testing.T) {
+	errs := validateRaw(t, map[string]*schema.Schema{"kubelet": schemaKubelet()}, map[string]interface{}{
+		"kubelet": []interface{}{
+			map[string]interface{}{
+				"feature_gates": map[string]interface{}{"ExperimentalCriticalPodAnnotation": "true"},
+			},
+		},
+	})
+	if len(errs) > 0 {
+		t.Errorf("expected kubelet feature_gates to be configurable, got %v", errs)
+	}
+}
+
+func TestSuppressEquivalentDuration(t *testing.T) {
+	tests := []struct {
+		old, new string
+		suppress bool
+	}{
+		{"1m0s", "60s", true},
+		{"1h0m0s", "1h", true},
+		{"2m0s", "2m", true},
+		{"1m0s", "90s", false},
+		{"", "60s", false},
+		{"1m0s", "", false},
+	}
+	for _, test := range tests {
+		if suppress := suppressEquivalentDuration("duration", test.old, test.new, nil); suppress != test.suppress {
+			t.Errorf("suppressEquivalentDuration(%q, %q) = %t, want %t", test.old, test.new, suppress, test.suppress)
+		}
+	}
+}
+
+func TestKubeletDurationsValidated(t *testing.T) {
+	errs := validateRaw(t, map[string]*schema.Schema{"kubelet": schemaKubelet()}, map[string]interface{}{
+		"kubelet": []interface{}{
+			map[string]interface{}{
+				"node_status_update_frequency": "10 seconds",
+			},
+		},
+	})
+	if len(errs) == 0 {
+		t.Error("expected the invalid kubelet node_status_update_frequency to be rejected")
+	}
+}
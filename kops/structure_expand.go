@@ -3,9 +3,11 @@ package kops
 import (
 	"encoding/json"
 	"log"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/hashicorp/terraform/helper/schema"
 	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	kopsapi "k8s.io/kops/pkg/apis/kops"
 )
@@ -28,16 +30,61 @@ func expandClusterSpec(data map[string]interface{}) kopsapi.ClusterSpec {
 		ap := expandStringMap(top)
 		clusterspec.AdditionalPolicies = &ap
 	}
+	if top, ok := data["addons"]; ok {
+		clusterspec.Addons = expandAddonSpec(top)
+	}
+	if top, ok := data["additional_sans"]; ok {
+		clusterspec.AdditionalSANs = expandStringSlice(top)
+	}
+	if top, ok := data["api"]; ok {
+		clusterspec.API = expandAPIAccessSpec(top.([]interface{}))
+	}
+	if top, ok := data["assets"]; ok {
+		clusterspec.Assets = expandAssets(top.([]interface{}))
+	}
+	if top, ok := data["authentication"]; ok {
+		clusterspec.Authentication = expandAuthenticationSpec(top.([]interface{}))
+	}
+	if top, ok := data["authorization"]; ok {
+		clusterspec.Authorization = expandAuthorizationSpec(top.([]interface{}))
+	}
 	clusterspec.Channel = data["channel"].(string)
+	if top, ok := data["cloud_config"]; ok {
+		clusterspec.CloudConfig = expandCloudConfiguration(top.([]interface{}))
+	}
+	if top, ok := data["cloud_labels"]; ok {
+		clusterspec.CloudLabels = expandStringMap(top)
+	}
 	clusterspec.CloudProvider = data["cloud_provider"].(string)
 	clusterspec.ClusterDNSDomain = data["cluster_dnsdomain"].(string)
 	clusterspec.ConfigBase = data["config_base"].(string)
 	clusterspec.ConfigStore = data["config_store"].(string)
 	clusterspec.DNSZone = data["dnszone"].(string)
+	if top, ok := data["docker"]; ok {
+		clusterspec.Docker = expandDockerConfig(top.([]interface{}))
+	}
+	if encryptionConfig, ok := data["encryption_config"].(bool); ok && encryptionConfig {
+		clusterspec.EncryptionConfig = &encryptionConfig
+	}
 	if top, ok := data["etcd_cluster"]; ok {
 		clusterspec.EtcdClusters = expandEtcdClusterSpec(top.([]interface{}))
 	}
+	if top, ok := data["external_dns"]; ok {
+		clusterspec.ExternalDNS = expandExternalDNSConfig(top.([]interface{}))
+	}
+	if top, ok := data["file_asset"]; ok {
+		clusterspec.FileAssets = expandFileAssetSpec(top.([]interface{}))
+	}
+	if top, ok := data["hook"]; ok {
+		clusterspec.Hooks = expandHookSpec(top.([]interface{}))
+	}
+	if top, ok := data["iam"]; ok {
+		clusterspec.IAM = expandIAMSpec(top.([]interface{}))
+	}
 	clusterspec.KeyStore = data["key_store"].(string)
+	if top, ok := data["kube_controller_manager"]; ok {
+		clusterspec.KubeControllerManager = expandKubeControllerManager(top.([]interface{}))
+	}
 	if top, ok := data["kube_api_server"]; ok {
 		clusterspec.KubeAPIServer = expandKubeApiServer(top.([]interface{}))
 	}
@@ -50,11 +97,17 @@ func expandClusterSpec(data map[string]interface{}) kopsapi.ClusterSpec {
 	if top, ok := data["kube_scheduler"]; ok {
 		clusterspec.KubeScheduler = expandKubeScheduler(top.([]interface{}))
 	}
+	if top, ok := data["kubelet"]; ok {
+		clusterspec.Kubelet = expandKubeletConfigSpec(top.([]interface{}))
+	}
 	if top, ok := data["kubernetes_api_access"]; ok {
 		clusterspec.KubernetesAPIAccess = expandStringSlice(top)
 	}
 	clusterspec.KubernetesVersion = data["kubernetes_version"].(string)
 	clusterspec.MasterInternalName = data["master_internal_name"].(string)
+	if top, ok := data["master_kubelet"]; ok {
+		clusterspec.MasterKubelet = expandKubeletConfigSpec(top.([]interface{}))
+	}
 	clusterspec.MasterPublicName = data["master_public_name"].(string)
 	clusterspec.NetworkCIDR = data["network_cidr"].(string)
 	clusterspec.NetworkID = data["network_id"].(string)
@@ -75,6 +128,7 @@ func expandClusterSpec(data map[string]interface{}) kopsapi.ClusterSpec {
 	if top, ok := data["topology"]; ok {
 		clusterspec.Topology = expandClusterTopology(top.([]interface{}))
 	}
+	clusterspec.UpdatePolicy = expandOptionalString(data["update_policy"])
 
 	spec, _ := json.Marshal(clusterspec)
 	log.Printf("[DEBUG] Spec: %s", string(spec))
@@ -82,6 +136,38 @@ func expandClusterSpec(data map[string]interface{}) kopsapi.ClusterSpec {
 	return clusterspec
 }
 
+func expandKubeControllerManager(data []interface{}) *kopsapi.KubeControllerManagerConfig {
+	if len(data) > 0 && data[0] != nil {
+		conv := data[0].(map[string]interface{})
+		return &kopsapi.KubeControllerManagerConfig{
+			AllocateNodeCIDRs:                     expandOptionalBool(conv["allocate_node_cidrs"]),
+			AttachDetachReconcileSyncPeriod:       expandOptionalDuration(conv["attach_detach_reconcile_sync_period"]),
+			CIDRAllocatorType:                     expandOptionalString(conv["cidr_allocator_type"]),
+			CloudProvider:                         conv["cloud_provider"].(string),
+			ClusterCIDR:                           conv["cluster_cidr"].(string),
+			ClusterName:                           conv["cluster_name"].(string),
+			ConfigureCloudRoutes:                  expandOptionalBool(conv["configure_cloud_routes"]),
+			FeatureGates:                          expandStringMap(conv["feature_gates"]),
+			HorizontalPodAutoscalerSyncPeriod:     expandOptionalDuration(conv["horizontal_pod_autoscaler_sync_period"]),
+			HorizontalPodAutoscalerDownscaleDelay: expandOptionalDuration(conv["horizontal_pod_autoscaler_downscale_delay"]),
+			HorizontalPodAutoscalerUpscaleDelay:   expandOptionalDuration(conv["horizontal_pod_autoscaler_upscale_delay"]),
+			HorizontalPodAutoscalerUseRestClients: expandOptionalBool(conv["horizontal_pod_autoscaler_use_rest_clients"]),
+			Image:                                 conv["image"].(string),
+			LeaderElection:                        expandLeaderElection(conv["leader_election"].([]interface{})),
+			LogLevel:                              int32(conv["log_level"].(int)),
+			Master:                                conv["master"].(string),
+			NodeMonitorGracePeriod:                expandOptionalDuration(conv["node_monitor_grace_period"]),
+			NodeMonitorPeriod:                     expandOptionalDuration(conv["node_monitor_period"]),
+			PodEvictionTimeout:                    expandOptionalDuration(conv["pod_eviction_timeout"]),
+			RootCAFile:                            conv["root_ca_file"].(string),
+			ServiceAccountPrivateKeyFile:          conv["service_account_private_key_file"].(string),
+			TerminatedPodGCThreshold:              expandOptionalInt32(conv["terminated_pod_gc_threshold"]),
+			UseServiceAccountCredentials:          expandOptionalBool(conv["use_service_account_credentials"]),
+		}
+	}
+	return nil
+}
+
 func expandKubeScheduler(data []interface{}) *kopsapi.KubeSchedulerConfig {
 	if len(data) > 0 {
 		conv := data[0].(map[string]interface{})
@@ -107,13 +193,165 @@ func expandLeaderElection(data []interface{}) *kopsapi.LeaderElectionConfigurati
 	return nil
 }
 
+func expandAddonSpec(data interface{}) []kopsapi.AddonSpec {
+	var addons []kopsapi.AddonSpec
+	for _, manifest := range expandStringSlice(data) {
+		addons = append(addons, kopsapi.AddonSpec{
+			Manifest: manifest,
+		})
+	}
+	return addons
+}
+
+func expandAPIAccessSpec(data []interface{}) *kopsapi.AccessSpec {
+	if len(data) > 0 && data[0] != nil {
+		conv := data[0].(map[string]interface{})
+		access := &kopsapi.AccessSpec{}
+		if conv["dns"].(bool) {
+			access.DNS = &kopsapi.DNSAccessSpec{}
+		}
+		access.LoadBalancer = expandLoadBalancerAccessSpec(conv["load_balancer"].([]interface{}))
+		return access
+	}
+	return nil
+}
+
+func expandLoadBalancerAccessSpec(data []interface{}) *kopsapi.LoadBalancerAccessSpec {
+	if len(data) > 0 {
+		conv := data[0].(map[string]interface{})
+		lb := &kopsapi.LoadBalancerAccessSpec{
+			Type:                     kopsapi.LoadBalancerType(conv["type"].(string)),
+			AdditionalSecurityGroups: expandStringSlice(conv["additional_security_groups"]),
+			UseForInternalApi:        conv["use_for_internal_api"].(bool),
+			SSLCertificate:           conv["ssl_certificate"].(string),
+		}
+		if timeout := int64(conv["idle_timeout_seconds"].(int)); timeout > 0 {
+			lb.IdleTimeoutSeconds = &timeout
+		}
+		return lb
+	}
+	return nil
+}
+
+func expandAuthenticationSpec(data []interface{}) *kopsapi.AuthenticationSpec {
+	if len(data) > 0 && data[0] != nil {
+		conv := data[0].(map[string]interface{})
+		switch conv["type"] {
+		case "aws":
+			return &kopsapi.AuthenticationSpec{
+				Aws: &kopsapi.AwsAuthenticationSpec{},
+			}
+		case "kopeio":
+			return &kopsapi.AuthenticationSpec{
+				Kopeio: &kopsapi.KopeioAuthenticationSpec{},
+			}
+		}
+	}
+	return nil
+}
+
+func expandAuthorizationSpec(data []interface{}) *kopsapi.AuthorizationSpec {
+	if len(data) > 0 && data[0] != nil {
+		conv := data[0].(map[string]interface{})
+		switch conv["type"] {
+		case "RBAC":
+			return &kopsapi.AuthorizationSpec{
+				RBAC: &kopsapi.RBACAuthorizationSpec{},
+			}
+		case "AlwaysAllow":
+			return &kopsapi.AuthorizationSpec{
+				AlwaysAllow: &kopsapi.AlwaysAllowAuthorizationSpec{},
+			}
+		}
+	}
+	return nil
+}
+
+func expandCloudConfiguration(data []interface{}) *kopsapi.CloudConfiguration {
+	if len(data) > 0 && data[0] != nil {
+		conv := data[0].(map[string]interface{})
+		return &kopsapi.CloudConfiguration{
+			Multizone:                   expandOptionalBool(conv["multizone"]),
+			NodeTags:                    expandOptionalString(conv["node_tags"]),
+			NodeInstancePrefix:          expandOptionalString(conv["node_instance_prefix"]),
+			DisableSecurityGroupIngress: expandOptionalBool(conv["disable_security_group_ingress"]),
+			ElbSecurityGroup:            expandOptionalString(conv["elb_security_group"]),
+		}
+	}
+	return nil
+}
+
+func expandDockerConfig(data []interface{}) *kopsapi.DockerConfig {
+	if len(data) > 0 && data[0] != nil {
+		conv := data[0].(map[string]interface{})
+		return &kopsapi.DockerConfig{
+			AuthorizationPlugins: expandStringSlice(conv["authorization_plugins"]),
+			Bridge:               expandOptionalString(conv["bridge"]),
+			BridgeIP:             expandOptionalString(conv["bridge_ip"]),
+			DataRoot:             expandOptionalString(conv["data_root"]),
+			DefaultUlimit:        expandStringSlice(conv["default_ulimit"]),
+			ExecRoot:             expandOptionalString(conv["exec_root"]),
+			Hosts:                expandStringSlice(conv["hosts"]),
+			IPMasq:               expandOptionalBool(conv["ip_masq"]),
+			IPTables:             expandOptionalBool(conv["ip_tables"]),
+			InsecureRegistry:     expandOptionalString(conv["insecure_registry"]),
+			LiveRestore:          expandOptionalBool(conv["live_restore"]),
+			LogDriver:            expandOptionalString(conv["log_driver"]),
+			LogLevel:             expandOptionalString(conv["log_level"]),
+			LogOpt:               expandStringSlice(conv["log_opt"]),
+			MTU:                  expandOptionalInt32(conv["mtu"]),
+			RegistryMirrors:      expandStringSlice(conv["registry_mirrors"]),
+			Storage:              expandOptionalString(conv["storage"]),
+			StorageOpts:          expandStringSlice(conv["storage_opts"]),
+			UserNamespaceRemap:   conv["user_namespace_remap"].(string),
+			Version:              expandOptionalString(conv["version"]),
+		}
+	}
+	return nil
+}
+
+func expandAssets(data []interface{}) *kopsapi.Assets {
+	if len(data) > 0 && data[0] != nil {
+		conv := data[0].(map[string]interface{})
+		return &kopsapi.Assets{
+			ContainerRegistry: expandOptionalString(conv["container_registry"]),
+			ContainerProxy:    expandOptionalString(conv["container_proxy"]),
+			FileRepository:    expandOptionalString(conv["file_repository"]),
+		}
+	}
+	return nil
+}
+
+func expandExternalDNSConfig(data []interface{}) *kopsapi.ExternalDNSConfig {
+	if len(data) > 0 && data[0] != nil {
+		conv := data[0].(map[string]interface{})
+		return &kopsapi.ExternalDNSConfig{
+			Disable:        conv["disable"].(bool),
+			WatchIngress:   expandOptionalBool(conv["watch_ingress"]),
+			WatchNamespace: conv["watch_namespace"].(string),
+		}
+	}
+	return nil
+}
+
+func expandIAMSpec(data []interface{}) *kopsapi.IAMSpec {
+	if len(data) > 0 && data[0] != nil {
+		conv := data[0].(map[string]interface{})
+		return &kopsapi.IAMSpec{
+			Legacy:                 conv["legacy"].(bool),
+			AllowContainerRegistry: conv["allow_container_registry"].(bool),
+		}
+	}
+	return nil
+}
+
 func expandKubeProxy(data []interface{}) *kopsapi.KubeProxyConfig {
 	if len(data) > 0 {
 		conv := data[0].(map[string]interface{})
 		return &kopsapi.KubeProxyConfig{
 			BindAddress:         conv["bind_address"].(string),
-			ConntrackMaxPerCore: expandInt32(conv["conntrack_max_per_core"]),
-			ConntrackMin:        expandInt32(conv["conntrack_min"]),
+			ConntrackMaxPerCore: expandOptionalInt32(conv["conntrack_max_per_core"]),
+			ConntrackMin:        expandOptionalInt32(conv["conntrack_min"]),
 			ClusterCIDR:         conv["cluster_cidr"].(string),
 			CPULimit:            conv["cpu_limit"].(string),
 			CPURequest:          conv["cpu_request"].(string),
@@ -152,16 +390,21 @@ func expandKubeDNS(data []interface{}) *kopsapi.KubeDNSConfig {
 func expandKubeApiServer(data []interface{}) *kopsapi.KubeAPIServerConfig {
 	if len(data) > 0 {
 		conv := data[0].(map[string]interface{})
+		minRequestTimeout := conv["min_request_timeout"]
+		if minRequestTimeout.(int) == 0 {
+			minRequestTimeout = conv["mix_request_timeout"]
+		}
 		return &kopsapi.KubeAPIServerConfig{
 			Address:                              conv["address"].(string),
-			APIServerCount:                       expandInt32(conv["api_server_count"]),
+			AdmissionControl:                     expandStringSlice(conv["admission_control"]),
+			APIServerCount:                       expandOptionalInt32(conv["api_server_count"]),
 			AuditLogFormat:                       expandString(conv["audit_log_format"]),
-			AuditLogMaxAge:                       expandInt32(conv["audit_log_max_age"]),
-			AuditLogMaxBackups:                   expandInt32(conv["audit_log_max_backups"]),
-			AuditLogMaxSize:                      expandInt32(conv["audit_log_max_size"]),
+			AuditLogMaxAge:                       expandOptionalInt32(conv["audit_log_max_age"]),
+			AuditLogMaxBackups:                   expandOptionalInt32(conv["audit_log_max_backups"]),
+			AuditLogMaxSize:                      expandOptionalInt32(conv["audit_log_max_size"]),
 			AuditLogPath:                         expandString(conv["audit_log_path"]),
 			AuditPolicyFile:                      conv["audit_policy_file"].(string),
-			AuthenticationTokenWebhookCacheTTL:   expandDuration(conv["authentication_token_webhook_cache_ttl"]),
+			AuthenticationTokenWebhookCacheTTL:   expandOptionalDuration(conv["authentication_token_webhook_cache_ttl"]),
 			AuthenticationTokenWebhookConfigFile: expandString(conv["authentication_token_webhook_config_file"]),
 			AuthorizationMode:                    expandString(conv["authorization_mode"]),
 			AuthorizationRBACSuperUser:           expandString(conv["authorization_rbac_super_user"]),
@@ -191,7 +434,7 @@ func expandKubeApiServer(data []interface{}) *kopsapi.KubeAPIServerConfig {
 			KubeletPreferredAddressTypes:         expandStringSlice(conv["kubelet_preferred_address_types"]),
 			LogLevel:                             int32(conv["log_level"].(int)),
 			MaxRequestsInflight:                  int32(conv["max_requests_inflight"].(int)),
-			MinRequestTimeout:                    expandInt32(conv["mix_request_timeout"]),
+			MinRequestTimeout:                    expandOptionalInt32(minRequestTimeout),
 			OIDCCAFile:                           expandString(conv["oidc_ca_file"]),
 			OIDCClientID:                         expandString(conv["oidc_client_id"]),
 			OIDCGroupsClaim:                      expandString(conv["oidc_groups_claim"]),
@@ -243,6 +486,9 @@ func expandStringMap(data interface{}) map[string]string {
 
 func expandStringSlice(data interface{}) []string {
 	var ret []string
+	if set, ok := data.(*schema.Set); ok {
+		data = set.List()
+	}
 	if data != nil {
 		d := data.([]interface{})
 		for _, val := range d {
@@ -269,11 +515,10 @@ func expandBastionSpec(data []interface{}) *kopsapi.BastionSpec {
 	if len(data) > 0 {
 		d := data[0].(map[string]interface{})
 		bastion := &kopsapi.BastionSpec{}
-		name := d["bastion_public_name"].(string)
-		timeout := int64(d["idle_timeout_seconds"].(int))
-
-		bastion.BastionPublicName = name
-		bastion.IdleTimeoutSeconds = &timeout
+		bastion.BastionPublicName = d["bastion_public_name"].(string)
+		if timeout := int64(d["idle_timeout_seconds"].(int)); timeout > 0 {
+			bastion.IdleTimeoutSeconds = &timeout
+		}
 		return bastion
 	}
 	return nil
@@ -299,10 +544,13 @@ func expandClusterSubnetSpec(data []interface{}) []kopsapi.ClusterSubnetSpec {
 	for _, s := range data {
 		conv := s.(map[string]interface{})
 		subnets = append(subnets, kopsapi.ClusterSubnetSpec{
-			Name: conv["name"].(string),
-			CIDR: conv["cidr"].(string),
-			Zone: conv["zone"].(string),
-			Type: stringToSubnetType(conv["type"].(string)),
+			Name:       conv["name"].(string),
+			CIDR:       conv["cidr"].(string),
+			Zone:       conv["zone"].(string),
+			Type:       stringToSubnetType(conv["type"].(string)),
+			ProviderID: conv["id"].(string),
+			Egress:     conv["egress"].(string),
+			PublicIP:   conv["public_ip"].(string),
 		})
 	}
 	return subnets
@@ -354,7 +602,7 @@ func expandNetworkingSpec(data []interface{}) *kopsapi.NetworkingSpec {
 		}
 	case "calico":
 		return &kopsapi.NetworkingSpec{
-			Calico: &kopsapi.CalicoNetworkingSpec{},
+			Calico: expandCalicoNetworkingSpec(spec["calico"].([]interface{})),
 		}
 	case "canal":
 		return &kopsapi.NetworkingSpec{
@@ -374,13 +622,57 @@ func expandNetworkingSpec(data []interface{}) *kopsapi.NetworkingSpec {
 		}
 	case "cilium":
 		return &kopsapi.NetworkingSpec{
-			Cilium: &kopsapi.CiliumNetworkingSpec{},
+			Cilium: expandCiliumNetworkingSpec(spec["cilium"].([]interface{})),
 		}
 	default:
 	}
 	return &kopsapi.NetworkingSpec{}
 }
 
+func expandCalicoNetworkingSpec(data []interface{}) *kopsapi.CalicoNetworkingSpec {
+	if len(data) > 0 && data[0] != nil {
+		conv := data[0].(map[string]interface{})
+		calico := &kopsapi.CalicoNetworkingSpec{
+			CrossSubnet:                     conv["cross_subnet"].(bool),
+			LogSeverityScreen:               conv["log_severity_screen"].(string),
+			PrometheusMetricsEnabled:        conv["prometheus_metrics_enabled"].(bool),
+			PrometheusMetricsPort:           int32(conv["prometheus_metrics_port"].(int)),
+			PrometheusGoMetricsEnabled:      conv["prometheus_go_metrics_enabled"].(bool),
+			PrometheusProcessMetricsEnabled: conv["prometheus_process_metrics_enabled"].(bool),
+		}
+		if mtu := conv["mtu"].(int); mtu > 0 {
+			calico.MTU = expandInt32(mtu)
+		}
+		return calico
+	}
+	return &kopsapi.CalicoNetworkingSpec{}
+}
+
+func expandCiliumNetworkingSpec(data []interface{}) *kopsapi.CiliumNetworkingSpec {
+	if len(data) > 0 && data[0] != nil {
+		conv := data[0].(map[string]interface{})
+		return &kopsapi.CiliumNetworkingSpec{
+			Version:                 conv["version"].(string),
+			AgentLabels:             expandStringSlice(conv["agent_labels"]),
+			AllowLocalhost:          conv["allow_localhost"].(string),
+			Debug:                   conv["debug"].(bool),
+			Device:                  conv["device"].(string),
+			DisableConntrack:        conv["disable_conntrack"].(bool),
+			DisableMasquerade:       conv["disable_masquerade"].(bool),
+			EnablePolicy:            conv["enable_policy"].(string),
+			EnableTracing:           conv["enable_tracing"].(bool),
+			Ipv4ClusterCIDRMaskSize: conv["ipv4_cluster_cidr_mask_size"].(int),
+			Ipv4Range:               conv["ipv4_range"].(string),
+			Ipv4ServiceRange:        conv["ipv4_service_range"].(string),
+			LB:                      conv["lb"].(string),
+			PrometheusServeAddr:     conv["prometheus_serve_addr"].(string),
+			SingleClusterRoute:      conv["single_cluster_route"].(bool),
+			Tunnel:                  conv["tunnel"].(string),
+		}
+	}
+	return &kopsapi.CiliumNetworkingSpec{}
+}
+
 func expandEtcdClusterSpec(data []interface{}) []*kopsapi.EtcdClusterSpec {
 	var spec []*kopsapi.EtcdClusterSpec
 
@@ -393,7 +685,7 @@ func expandEtcdClusterSpec(data []interface{}) []*kopsapi.EtcdClusterSpec {
 		enableTLS := top["enable_etcd_tls"].(bool)
 		enableTLSAuth := top["enable_tls_auth"].(bool)
 
-		spec = append(spec, &kopsapi.EtcdClusterSpec{
+		etcdCluster := &kopsapi.EtcdClusterSpec{
 			Name:          name,
 			EnableEtcdTLS: enableTLS,
 			EnableTLSAuth: enableTLSAuth,
@@ -402,7 +694,15 @@ func expandEtcdClusterSpec(data []interface{}) []*kopsapi.EtcdClusterSpec {
 			Members:       expandEtcdMemberSpec(top["etcd_member"].([]interface{})),
 			Manager:       expandEtcdManagerSpec(top["manager"].([]interface{})),
 			Backups:       expandEtcdBackupSpec(top["backups"].([]interface{})),
-		})
+		}
+		if timeout := top["leader_election_timeout"].(string); timeout != "" {
+			etcdCluster.LeaderElectionTimeout = expandDuration(timeout)
+		}
+		if interval := top["heartbeat_interval"].(string); interval != "" {
+			etcdCluster.HeartbeatInterval = expandDuration(interval)
+		}
+
+		spec = append(spec, etcdCluster)
 	}
 
 	return spec
@@ -439,21 +739,27 @@ func expandEtcdMemberSpec(data []interface{}) []*kopsapi.EtcdMemberSpec {
 
 		name := member["name"].(string)
 		instanceGroup := member["instance_group"].(string)
-		volumeType := member["volume_type"].(string)
-		volumeIops := int32(member["volume_iops"].(int))
-		volumeSize := int32(member["volume_size"].(int))
-		kmsKeyID := member["kms_key_id"].(string)
 		encryptedVolume := member["encrypted_volume"].(bool)
 
-		spec = append(spec, &kopsapi.EtcdMemberSpec{
+		memberSpec := &kopsapi.EtcdMemberSpec{
 			Name:            name,
 			InstanceGroup:   &instanceGroup,
-			VolumeType:      &volumeType,
-			VolumeIops:      &volumeIops,
-			VolumeSize:      &volumeSize,
-			KmsKeyId:        &kmsKeyID,
 			EncryptedVolume: &encryptedVolume,
-		})
+		}
+		if volumeType := member["volume_type"].(string); volumeType != "" {
+			memberSpec.VolumeType = &volumeType
+		}
+		if volumeIops := member["volume_iops"].(int); volumeIops > 0 {
+			memberSpec.VolumeIops = expandInt32(volumeIops)
+		}
+		if volumeSize := member["volume_size"].(int); volumeSize > 0 {
+			memberSpec.VolumeSize = expandInt32(volumeSize)
+		}
+		if kmsKeyID := member["kms_key_id"].(string); kmsKeyID != "" {
+			memberSpec.KmsKeyId = &kmsKeyID
+		}
+
+		spec = append(spec, memberSpec)
 	}
 
 	return spec
@@ -466,22 +772,10 @@ func expandInstanceGroupSpec(data map[string]interface{}) kopsapi.InstanceGroupS
 	ig.Image = data["image"].(string)
 	ig.Subnets = expandStringSlice(data["subnets"])
 	ig.Zones = expandStringSlice(data["zones"])
-	if rvs, ok := data["root_volume_size"]; ok {
-		volumeSize := int32(rvs.(int))
-		ig.RootVolumeSize = &volumeSize
-	}
-	if rvt, ok := data["root_volume_type"]; ok {
-		volumeType := rvt.(string)
-		ig.RootVolumeType = &volumeType
-	}
-	if rvi, ok := data["root_volume_iops"]; ok {
-		volumeIOPS := int32(rvi.(int))
-		ig.RootVolumeIops = &volumeIOPS
-	}
-	if rvo, ok := data["root_volume_optimization"]; ok {
-		volumeOptimization := rvo.(bool)
-		ig.RootVolumeOptimization = &volumeOptimization
-	}
+	ig.RootVolumeSize = expandOptionalInt32(data["root_volume_size"])
+	ig.RootVolumeType = expandOptionalString(data["root_volume_type"])
+	ig.RootVolumeIops = expandOptionalInt32(data["root_volume_iops"])
+	ig.RootVolumeOptimization = expandOptionalBool(data["root_volume_optimization"])
 	if ms, ok := data["min_size"]; ok {
 		minSize := int32(ms.(int))
 		ig.MinSize = &minSize
@@ -490,88 +784,98 @@ func expandInstanceGroupSpec(data map[string]interface{}) kopsapi.InstanceGroupS
 		maxSize := int32(ms.(int))
 		ig.MaxSize = &maxSize
 	}
+	ig.MaxPrice = expandOptionalString(data["max_price"])
 	if cl, ok := data["cloud_labels"]; ok {
 		ig.CloudLabels = expandStringMap(cl)
 	}
 	if nl, ok := data["node_labels"]; ok {
 		ig.NodeLabels = expandStringMap(nl)
 	}
+	if t, ok := data["taints"]; ok {
+		ig.Taints = expandStringSlice(t)
+	}
 
 	ig.AdditionalSecurityGroups = expandStringSlice(data["additional_security_groups"])
 	ig.AdditionalUserData = expandAdditionalUserData(data["additional_user_data"].([]interface{}))
-	ig.AssociatePublicIP = expandBool(data["associate_public_ip"])
+	// kops associates public IPs with the instances of public subnets unless it is disabled
+	if associatePublicIP := data["associate_public_ip"].(bool); !associatePublicIP {
+		ig.AssociatePublicIP = &associatePublicIP
+	}
 	ig.DetailedInstanceMonitoring = expandBool(data["detailed_instance_monitoring"])
 	ig.ExternalLoadBalancers = expandExternalLoadBalancers(data["external_load_balancer"].([]interface{}))
 	ig.FileAssets = expandFileAssetSpec(data["file_asset"].([]interface{}))
 	ig.Hooks = expandHookSpec(data["hook"].([]interface{}))
+	ig.IAM = expandIAMProfileSpec(data["iam"].([]interface{}))
 	ig.Kubelet = expandKubeletConfigSpec(data["kubelet"].([]interface{}))
+	ig.SuspendProcesses = expandStringSlice(data["suspend_processes"])
+	ig.Tenancy = data["tenancy"].(string)
 	return ig
 }
 
 func expandKubeletConfigSpec(data []interface{}) *kopsapi.KubeletConfigSpec {
-	if len(data) > 0 {
+	if len(data) > 0 && data[0] != nil {
 		d := data[0].(map[string]interface{})
 
 		return &kopsapi.KubeletConfigSpec{
 			APIServers:                         d["api_servers"].(string),
 			AuthorizationMode:                  d["authorization_mode"].(string),
-			AllowPrivileged:                    expandBool(d["allow_privileged"]),
-			AnonymousAuth:                      expandBool(d["anonymous_auth"]),
-			AuthenticationTokenWebhook:         expandBool(d["authentication_token_webhook"]),
-			AuthenticationTokenWebhookCacheTTL: expandDuration(d["authentication_token_webhook_cache_ttl"]),
-			BabysitDaemons:                     expandBool(d["babysit_daemons"]),
+			AllowPrivileged:                    expandTriStateBool(d["allow_privileged"]),
+			AnonymousAuth:                      expandTriStateBool(d["anonymous_auth"]),
+			AuthenticationTokenWebhook:         expandTriStateBool(d["authentication_token_webhook"]),
+			AuthenticationTokenWebhookCacheTTL: expandOptionalDuration(d["authentication_token_webhook_cache_ttl"]),
+			BabysitDaemons:                     expandTriStateBool(d["babysit_daemons"]),
 			BootstrapKubeconfig:                d["bootstrap_kubeconfig"].(string),
 			CgroupRoot:                         d["cgroup_root"].(string),
 			ClientCAFile:                       d["client_ca_file"].(string),
 			CloudProvider:                      d["cloud_provider"].(string),
 			ClusterDNS:                         d["cluster_dns"].(string),
 			ClusterDomain:                      d["cluster_domain"].(string),
-			ConfigureCBR0:                      expandBool(d["configure_cbr0"]),
-			DockerDisableSharedPID:             expandBool(d["docker_disable_shared_pid"]),
-			EnableCustomMetrics:                expandBool(d["enable_custom_metrics"]),
-			EnableDebuggingHandlers:            expandBool(d["enable_debugging_handlers"]),
+			ConfigureCBR0:                      expandTriStateBool(d["configure_cbr0"]),
+			DockerDisableSharedPID:             expandTriStateBool(d["docker_disable_shared_pid"]),
+			EnableCustomMetrics:                expandTriStateBool(d["enable_custom_metrics"]),
+			EnableDebuggingHandlers:            expandTriStateBool(d["enable_debugging_handlers"]),
 			EnforceNodeAllocatable:             d["enforce_node_allocatable"].(string),
-			EvictionHard:                       expandString(d["eviction_hard"]),
+			EvictionHard:                       expandOptionalString(d["eviction_hard"]),
 			EvictionMaxPodGracePeriod:          int32(d["eviction_max_pod_grace_period"].(int)),
 			EvictionMinimumReclaim:             d["eviction_minimum_reclaim"].(string),
-			EvictionPressureTransitionPeriod:   expandDuration(d["eviction_pressure_transition_period"]),
+			EvictionPressureTransitionPeriod:   expandOptionalDuration(d["eviction_pressure_transition_period"]),
 			EvictionSoft:                       d["eviction_soft"].(string),
 			EvictionSoftGracePeriod:            d["eviction_soft_grace_period"].(string),
 			ExperimentalAllowedUnsafeSysctls:   expandStringSlice(d["experimental_allowed_unsafe_sysctls"]),
-			FailSwapOn:                         expandBool(d["fail_swap_on"]),
+			FailSwapOn:                         expandTriStateBool(d["fail_swap_on"]),
 			FeatureGates:                       expandStringMap(d["feature_gates"]),
 			HairpinMode:                        d["hairpin_mode"].(string),
 			HostnameOverride:                   d["hostname_override"].(string),
-			ImageGCHighThresholdPercent:        expandInt32(d["image_gc_high_threshold_percent"]),
-			ImageGCLowThresholdPercent:         expandInt32(d["image_gc_low_threshold_percent"]),
-			ImagePullProgressDeadline:          expandDuration(d["image_pull_progress_deadline"]),
+			ImageGCHighThresholdPercent:        expandTriStateInt32(d["image_gc_high_threshold_percent"]),
+			ImageGCLowThresholdPercent:         expandTriStateInt32(d["image_gc_low_threshold_percent"]),
+			ImagePullProgressDeadline:          expandOptionalDuration(d["image_pull_progress_deadline"]),
 			KubeconfigPath:                     d["kubeconfig_path"].(string),
 			KubeletCgroups:                     d["kubelet_cgroups"].(string),
 			KubeReserved:                       expandStringMap(d["kube_reserved"]),
 			KubeReservedCgroup:                 d["kube_reserved_cgroup"].(string),
-			LogLevel:                           expandInt32(d["log_level"]),
-			MaxPods:                            expandInt32(d["max_pods"]),
-			NetworkPluginMTU:                   expandInt32(d["network_plugin_mtu"]),
+			LogLevel:                           expandTriStateInt32(d["log_level"]),
+			MaxPods:                            expandTriStateInt32(d["max_pods"]),
+			NetworkPluginMTU:                   expandTriStateInt32(d["network_plugin_mtu"]),
 			NetworkPluginName:                  d["network_plugin_name"].(string),
 			NodeLabels:                         expandStringMap(d["node_labels"]),
-			NodeStatusUpdateFrequency:          expandDuration(d["node_status_update_frequency"]),
+			NodeStatusUpdateFrequency:          expandOptionalDuration(d["node_status_update_frequency"]),
 			NonMasqueradeCIDR:                  d["non_masquerade_cidr"].(string),
-			NvidiaGPUs:                         *expandInt32(d["nvidia_gpus"]),
+			NvidiaGPUs:                         int32(d["nvidia_gpus"].(int)),
 			PodCIDR:                            d["pod_cidr"].(string),
 			PodInfraContainerImage:             d["pod_infra_container_image"].(string),
 			PodManifestPath:                    d["pod_manifest_path"].(string),
-			ReadOnlyPort:                       expandInt32(d["read_only_port"]),
-			ReconcileCIDR:                      expandBool(d["reconcile_cidr"]),
-			RegisterNode:                       expandBool(d["register_node"]),
-			RegisterSchedulable:                expandBool(d["register_schedulable"]),
-			RequireKubeconfig:                  expandBool(d["require_kubeconfig"]),
-			ResolverConfig:                     expandString(d["resolver_config"]),
+			ReadOnlyPort:                       expandTriStateInt32(d["read_only_port"]),
+			ReconcileCIDR:                      expandTriStateBool(d["reconcile_cidr"]),
+			RegisterNode:                       expandTriStateBool(d["register_node"]),
+			RegisterSchedulable:                expandTriStateBool(d["register_schedulable"]),
+			RequireKubeconfig:                  expandTriStateBool(d["require_kubeconfig"]),
+			ResolverConfig:                     expandOptionalString(d["resolver_config"]),
 			RootDir:                            d["root_dir"].(string),
-			RuntimeRequestTimeout:              expandDuration(d["runtime_request_timeout"]),
+			RuntimeRequestTimeout:              expandOptionalDuration(d["runtime_request_timeout"]),
 			RuntimeCgroups:                     d["runtime_cgroups"].(string),
-			SeccompProfileRoot:                 expandString(d["seccomp_profile_root"]),
-			SerializeImagePulls:                expandBool(d["serialize_image_pulls"]),
-			StreamingConnectionIdleTimeout:     expandDuration(d["streaming_connection_idle_timeout"]),
+			SeccompProfileRoot:                 expandOptionalString(d["seccomp_profile_root"]),
+			SerializeImagePulls:                expandTriStateBool(d["serialize_image_pulls"]),
+			StreamingConnectionIdleTimeout:     expandOptionalDuration(d["streaming_connection_idle_timeout"]),
 			SystemCgroups:                      d["system_cgroups"].(string),
 			SystemReserved:                     expandStringMap(d["system_reserved"]),
 			SystemReservedCgroup:               d["system_reserved_cgroup"].(string),
@@ -579,7 +883,7 @@ func expandKubeletConfigSpec(data []interface{}) *kopsapi.KubeletConfigSpec {
 			TLSCertFile:                        d["tls_cert_file"].(string),
 			TLSPrivateKeyFile:                  d["tls_private_key_file"].(string),
 			VolumePluginDirectory:              d["volume_plugin_directory"].(string),
-			VolumeStatsAggPeriod:               expandDuration(d["volume_stats_agg_period"]),
+			VolumeStatsAggPeriod:               expandOptionalDuration(d["volume_stats_agg_period"]),
 		}
 	}
 	return nil
@@ -593,6 +897,24 @@ func expandInt32(data interface{}) *int32 {
 	return nil
 }
 
+// expandOptionalInt32 leaves the value unset when it is zero, so kops does not render the flag
+func expandOptionalInt32(data interface{}) *int32 {
+	if data != nil && data.(int) != 0 {
+		return expandInt32(data)
+	}
+	return nil
+}
+
+// expandTriStateInt32 parses the string of a tri-state int, empty strings are unset
+func expandTriStateInt32(data interface{}) *int32 {
+	if data != nil && data.(string) != "" {
+		parsed, _ := strconv.ParseInt(data.(string), 10, 32)
+		value := int32(parsed)
+		return &value
+	}
+	return nil
+}
+
 func expandString(data interface{}) *string {
 	if data != nil {
 		parsed := data.(string)
@@ -601,6 +923,29 @@ func expandString(data interface{}) *string {
 	return nil
 }
 
+func expandOptionalString(data interface{}) *string {
+	if data != nil && data.(string) != "" {
+		return expandString(data)
+	}
+	return nil
+}
+
+func expandOptionalBool(data interface{}) *bool {
+	if data != nil && data.(bool) {
+		return expandBool(data)
+	}
+	return nil
+}
+
+// expandTriStateBool parses the string of a tri-state bool, empty strings are unset
+func expandTriStateBool(data interface{}) *bool {
+	if data != nil && data.(string) != "" {
+		parsed, _ := strconv.ParseBool(data.(string))
+		return &parsed
+	}
+	return nil
+}
+
 func expandBool(data interface{}) *bool {
 	if data != nil {
 		parsed := data.(bool)
@@ -617,6 +962,14 @@ func expandDuration(data interface{}) *v1.Duration {
 	return nil
 }
 
+// expandOptionalDuration leaves the value unset when it is empty, so kops does not render the flag
+func expandOptionalDuration(data interface{}) *v1.Duration {
+	if data != nil && data.(string) != "" {
+		return expandDuration(data)
+	}
+	return nil
+}
+
 func expandHookSpec(data []interface{}) []kopsapi.HookSpec {
 	var hooks []kopsapi.HookSpec
 
@@ -690,6 +1043,17 @@ func expandFileAssetSpec(data []interface{}) []kopsapi.FileAssetSpec {
 	return fileAssets
 }
 
+func expandIAMProfileSpec(data []interface{}) *kopsapi.IAMProfileSpec {
+	if len(data) > 0 && data[0] != nil {
+		conv := data[0].(map[string]interface{})
+		profile := conv["profile"].(string)
+		return &kopsapi.IAMProfileSpec{
+			Profile: &profile,
+		}
+	}
+	return nil
+}
+
 func expandExternalLoadBalancers(data []interface{}) []kopsapi.LoadBalancer {
 	var loadBalancers []kopsapi.LoadBalancer
 
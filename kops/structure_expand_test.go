@@ -0,0 +1,64 @@
+package kops
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func TestKubeletConfigSpecRoundTrip(t *testing.T) {
+	resource := map[string]*schema.Schema{"kubelet": schemaKubelet()}
+	d := schema.TestResourceDataRaw(t, resource, map[string]interface{}{
+		"kubelet": []interface{}{
+			map[string]interface{}{
+				"serialize_image_pulls": false,
+				"anonymous_auth":        true,
+				"read_only_port":        0,
+				"max_pods":              110,
+				"feature_gates":         map[string]interface{}{"ExperimentalCriticalPodAnnotation": "true"},
+			},
+		},
+	})
+
+	spec := expandKubeletConfigSpec(d.Get("kubelet").([]interface{}))
+	if spec.SerializeImagePulls == nil || *spec.SerializeImagePulls {
+		t.Errorf("expected serialize_image_pulls to be false, got %v", spec.SerializeImagePulls)
+	}
+	if spec.AnonymousAuth == nil || !*spec.AnonymousAuth {
+		t.Errorf("expected anonymous_auth to be true, got %v", spec.AnonymousAuth)
+	}
+	if spec.ReadOnlyPort == nil || *spec.ReadOnlyPort != 0 {
+		t.Errorf("expected read_only_port to be 0, got %v", spec.ReadOnlyPort)
+	}
+	if spec.MaxPods == nil || *spec.MaxPods != 110 {
+		t.Errorf("expected max_pods to be 110, got %v", spec.MaxPods)
+	}
+	if spec.FeatureGates["ExperimentalCriticalPodAnnotation"] != "true" {
+		t.Errorf("expected feature_gates to be set, got %v", spec.FeatureGates)
+	}
+	if spec.FailSwapOn != nil || spec.RegisterNode != nil || spec.LogLevel != nil {
+		t.Errorf("expected unset arguments to stay unset, got fail_swap_on %v, register_node %v, log_level %v", spec.FailSwapOn, spec.RegisterNode, spec.LogLevel)
+	}
+
+	if err := d.Set("kubelet", flattenKubeletSpec(spec)); err != nil {
+		t.Fatal(err)
+	}
+	flattened := d.Get("kubelet").([]interface{})[0].(map[string]interface{})
+	expected := map[string]string{
+		"serialize_image_pulls": "false",
+		"anonymous_auth":        "true",
+		"read_only_port":        "0",
+		"max_pods":              "110",
+		"fail_swap_on":          "",
+		"log_level":             "",
+	}
+	for key, value := range expected {
+		if flattened[key] != value {
+			t.Errorf("expected %s to read back as %q, got %q", key, value, flattened[key])
+		}
+	}
+
+	if again := expandKubeletConfigSpec(d.Get("kubelet").([]interface{})); *again.SerializeImagePulls || *again.ReadOnlyPort != 0 || again.FailSwapOn != nil {
+		t.Errorf("expected the flattened kubelet to expand to the same spec, got %+v", again)
+	}
+}
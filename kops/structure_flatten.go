@@ -1,9 +1,12 @@
 package kops
 
 import (
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
 	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	kopsapi "k8s.io/kops/pkg/apis/kops"
-	"strings"
 )
 
 func flattenObjectMeta(cluster v1.ObjectMeta) []map[string]interface{} {
@@ -24,10 +27,32 @@ func flattenClusterSpec(cluster kopsapi.ClusterSpec) []map[string]interface{} {
 	data["config_base"] = cluster.ConfigBase
 	data["config_store"] = cluster.ConfigStore
 	data["dnszone"] = cluster.DNSZone
+	if cluster.Docker != nil {
+		data["docker"] = flattenDockerConfig(cluster.Docker)
+	}
+	if cluster.ExternalDNS != nil {
+		data["external_dns"] = flattenExternalDNSConfig(cluster.ExternalDNS)
+	}
+	data["file_asset"] = flattenFileAsset(cluster.FileAssets)
+	data["hook"] = flattenHook(cluster.Hooks)
+	if cluster.EncryptionConfig != nil {
+		data["encryption_config"] = *cluster.EncryptionConfig
+	}
+	if cluster.IAM != nil {
+		data["iam"] = []map[string]interface{}{
+			{
+				"legacy":                   cluster.IAM.Legacy,
+				"allow_container_registry": cluster.IAM.AllowContainerRegistry,
+			},
+		}
+	}
 	data["key_store"] = cluster.KeyStore
 	if cluster.KubeAPIServer != nil {
 		data["kube_api_server"] = flattenKubeApiServer(cluster.KubeAPIServer)
 	}
+	if cluster.KubeControllerManager != nil {
+		data["kube_controller_manager"] = flattenKubeControllerManager(cluster.KubeControllerManager)
+	}
 	if cluster.KubeDNS != nil {
 		data["kube_dns"] = flattenKubeDNS(cluster.KubeDNS)
 	}
@@ -37,8 +62,14 @@ func flattenClusterSpec(cluster kopsapi.ClusterSpec) []map[string]interface{} {
 	if cluster.KubeScheduler != nil {
 		data["kube_scheduler"] = flattenKubeScheduler(cluster.KubeScheduler)
 	}
+	if cluster.Kubelet != nil {
+		data["kubelet"] = flattenKubeletSpec(cluster.Kubelet)
+	}
 	data["kubernetes_version"] = cluster.KubernetesVersion
 	data["master_internal_name"] = cluster.MasterInternalName
+	if cluster.MasterKubelet != nil {
+		data["master_kubelet"] = flattenKubeletSpec(cluster.MasterKubelet)
+	}
 	data["master_public_name"] = cluster.MasterPublicName
 	data["network_cidr"] = cluster.NetworkCIDR
 	data["network_id"] = cluster.NetworkID
@@ -52,38 +83,95 @@ func flattenClusterSpec(cluster kopsapi.ClusterSpec) []map[string]interface{} {
 	if cluster.Topology != nil {
 		data["topology"] = flattenClusterTopology(cluster.Topology)
 	}
+	if cluster.UpdatePolicy != nil {
+		data["update_policy"] = *cluster.UpdatePolicy
+	}
 	data["ssh_access"] = cluster.SSHAccess
+	data["additional_sans"] = cluster.AdditionalSANs
+	data["addons"] = flattenAddonSpec(cluster.Addons)
+	if cluster.Authentication != nil && !cluster.Authentication.IsEmpty() {
+		data["authentication"] = flattenAuthenticationSpec(cluster.Authentication)
+	}
+	if cluster.Authorization != nil && !cluster.Authorization.IsEmpty() {
+		data["authorization"] = flattenAuthorizationSpec(cluster.Authorization)
+	}
+	if cluster.CloudConfig != nil {
+		data["cloud_config"] = flattenCloudConfiguration(cluster.CloudConfig)
+	}
+	data["cloud_labels"] = cluster.CloudLabels
+	if cluster.API != nil {
+		data["api"] = flattenAPIAccessSpec(cluster.API)
+	}
+	if cluster.Assets != nil {
+		data["assets"] = flattenAssets(cluster.Assets)
+	}
 	data["kubernetes_api_access"] = cluster.KubernetesAPIAccess
-	data["additional_policies"] = *cluster.AdditionalPolicies
+	if cluster.AdditionalPolicies != nil {
+		data["additional_policies"] = *cluster.AdditionalPolicies
+	}
 	data["etcd_cluster"] = flattenEtcdClusterSpec(cluster.EtcdClusters)
 
 	return []map[string]interface{}{data}
 }
 
+// flattenClusterComputedSpec exposes the values kops resolved when populating the cluster spec
+func flattenClusterComputedSpec(cluster kopsapi.ClusterSpec) []map[string]interface{} {
+	data := make(map[string]interface{})
+
+	data["kubernetes_version"] = cluster.KubernetesVersion
+	data["config_base"] = cluster.ConfigBase
+	data["master_public_name"] = cluster.MasterPublicName
+	data["master_internal_name"] = cluster.MasterInternalName
+	data["network_cidr"] = cluster.NetworkCIDR
+	data["non_masquerade_cidr"] = cluster.NonMasqueradeCIDR
+	data["service_cluster_ip_range"] = cluster.ServiceClusterIPRange
+	if cluster.KubeControllerManager != nil {
+		data["pod_cidr"] = cluster.KubeControllerManager.ClusterCIDR
+	}
+	if cluster.KubeDNS != nil {
+		data["dns_server_ip"] = cluster.KubeDNS.ServerIP
+	}
+	if cluster.KubeAPIServer != nil {
+		data["kube_api_server_image"] = cluster.KubeAPIServer.Image
+	}
+
+	versions := make(map[string]interface{})
+	images := make(map[string]interface{})
+	for _, etcd := range cluster.EtcdClusters {
+		versions[etcd.Name] = etcd.Version
+		images[etcd.Name] = etcd.Image
+	}
+	data["etcd_versions"] = versions
+	data["etcd_images"] = images
+
+	return []map[string]interface{}{data}
+}
+
 func flattenKubeApiServer(api *kopsapi.KubeAPIServerConfig) []map[string]interface{} {
 	data := make(map[string]interface{})
 	data["address"] = api.Address
+	data["admission_control"] = api.AdmissionControl
 	if api.APIServerCount != nil {
-		data["api_server_count"] = *api.APIServerCount
+		data["api_server_count"] = int(*api.APIServerCount)
 	}
 	if api.AuditLogFormat != nil {
 		data["audit_log_format"] = *api.AuditLogFormat
 	}
 	if api.AuditLogMaxAge != nil {
-		data["audit_log_max_age"] = *api.AuditLogMaxAge
+		data["audit_log_max_age"] = int(*api.AuditLogMaxAge)
 	}
 	if api.AuditLogMaxBackups != nil {
-		data["audit_log_max_backups"] = *api.AuditLogMaxBackups
+		data["audit_log_max_backups"] = int(*api.AuditLogMaxBackups)
 	}
 	if api.AuditLogMaxSize != nil {
-		data["audit_log_max_size"] = *api.AuditLogMaxSize
+		data["audit_log_max_size"] = int(*api.AuditLogMaxSize)
 	}
 	if api.AuditLogPath != nil {
 		data["audit_log_path"] = *api.AuditLogPath
 	}
 	data["audit_policy_file"] = api.AuditPolicyFile
 	if api.AuthenticationTokenWebhookCacheTTL != nil {
-		data["authentication_token_webhook_cache_ttl"] = *api.AuthenticationTokenWebhookCacheTTL
+		data["authentication_token_webhook_cache_ttl"] = api.AuthenticationTokenWebhookCacheTTL.Duration.String()
 	}
 	if api.AuthenticationTokenWebhookConfigFile != nil {
 		data["authentication_token_webhook_config_file"] = *api.AuthenticationTokenWebhookConfigFile
@@ -133,6 +221,7 @@ func flattenKubeApiServer(api *kopsapi.KubeAPIServerConfig) []map[string]interfa
 	data["log_level"] = int(api.LogLevel)
 	data["max_requests_inflight"] = int(api.MaxRequestsInflight)
 	if api.MinRequestTimeout != nil {
+		data["min_request_timeout"] = int(*api.MinRequestTimeout)
 		data["mix_request_timeout"] = int(*api.MinRequestTimeout)
 	}
 	if api.OIDCCAFile != nil {
@@ -206,17 +295,15 @@ func flattenKubeProxy(proxy *kopsapi.KubeProxyConfig) []map[string]interface{} {
 	data := make(map[string]interface{})
 	data["bind_address"] = proxy.BindAddress
 	if proxy.ConntrackMaxPerCore != nil {
-		data["conntrack_max_per_core"] = proxy.ConntrackMaxPerCore
+		data["conntrack_max_per_core"] = int(*proxy.ConntrackMaxPerCore)
 	}
 	if proxy.ConntrackMin != nil {
-		data["conntrack_min"] = proxy.ConntrackMin
+		data["conntrack_min"] = int(*proxy.ConntrackMin)
 	}
 	data["cluster_cidr"] = proxy.ClusterCIDR
 	data["cpu_limit"] = proxy.CPULimit
 	data["cpu_request"] = proxy.CPURequest
-	if proxy.Enabled != nil {
-		data["enabled"] = proxy.Enabled
-	}
+	data["enabled"] = proxy.Enabled == nil || *proxy.Enabled
 	data["feature_gates"] = proxy.FeatureGates
 	data["hostname_override"] = proxy.HostnameOverride
 	data["image"] = proxy.Image
@@ -228,6 +315,62 @@ func flattenKubeProxy(proxy *kopsapi.KubeProxyConfig) []map[string]interface{} {
 	return []map[string]interface{}{data}
 }
 
+func flattenKubeControllerManager(kcm *kopsapi.KubeControllerManagerConfig) []map[string]interface{} {
+	data := make(map[string]interface{})
+	if kcm.AllocateNodeCIDRs != nil {
+		data["allocate_node_cidrs"] = *kcm.AllocateNodeCIDRs
+	}
+	if kcm.AttachDetachReconcileSyncPeriod != nil {
+		data["attach_detach_reconcile_sync_period"] = kcm.AttachDetachReconcileSyncPeriod.Duration.String()
+	}
+	if kcm.CIDRAllocatorType != nil {
+		data["cidr_allocator_type"] = *kcm.CIDRAllocatorType
+	}
+	data["cloud_provider"] = kcm.CloudProvider
+	data["cluster_cidr"] = kcm.ClusterCIDR
+	data["cluster_name"] = kcm.ClusterName
+	if kcm.ConfigureCloudRoutes != nil {
+		data["configure_cloud_routes"] = *kcm.ConfigureCloudRoutes
+	}
+	data["feature_gates"] = kcm.FeatureGates
+	if kcm.HorizontalPodAutoscalerSyncPeriod != nil {
+		data["horizontal_pod_autoscaler_sync_period"] = kcm.HorizontalPodAutoscalerSyncPeriod.Duration.String()
+	}
+	if kcm.HorizontalPodAutoscalerDownscaleDelay != nil {
+		data["horizontal_pod_autoscaler_downscale_delay"] = kcm.HorizontalPodAutoscalerDownscaleDelay.Duration.String()
+	}
+	if kcm.HorizontalPodAutoscalerUpscaleDelay != nil {
+		data["horizontal_pod_autoscaler_upscale_delay"] = kcm.HorizontalPodAutoscalerUpscaleDelay.Duration.String()
+	}
+	if kcm.HorizontalPodAutoscalerUseRestClients != nil {
+		data["horizontal_pod_autoscaler_use_rest_clients"] = *kcm.HorizontalPodAutoscalerUseRestClients
+	}
+	data["image"] = kcm.Image
+	if kcm.LeaderElection != nil {
+		data["leader_election"] = flattenLeaderElection(kcm.LeaderElection)
+	}
+	data["log_level"] = int(kcm.LogLevel)
+	data["master"] = kcm.Master
+	if kcm.NodeMonitorGracePeriod != nil {
+		data["node_monitor_grace_period"] = kcm.NodeMonitorGracePeriod.Duration.String()
+	}
+	if kcm.NodeMonitorPeriod != nil {
+		data["node_monitor_period"] = kcm.NodeMonitorPeriod.Duration.String()
+	}
+	if kcm.PodEvictionTimeout != nil {
+		data["pod_eviction_timeout"] = kcm.PodEvictionTimeout.Duration.String()
+	}
+	data["root_ca_file"] = kcm.RootCAFile
+	data["service_account_private_key_file"] = kcm.ServiceAccountPrivateKeyFile
+	if kcm.TerminatedPodGCThreshold != nil {
+		data["terminated_pod_gc_threshold"] = int(*kcm.TerminatedPodGCThreshold)
+	}
+	if kcm.UseServiceAccountCredentials != nil {
+		data["use_service_account_credentials"] = *kcm.UseServiceAccountCredentials
+	}
+	return []map[string]interface{}{data}
+}
+
 func flattenKubeScheduler(scheduler *kopsapi.KubeSchedulerConfig) []map[string]interface{} {
 	data := make(map[string]interface{})
 	data["feature_gates"] = scheduler.FeatureGates
@@ -277,6 +420,7 @@ func flattenNetworkingSpec(spec *kopsapi.NetworkingSpec) []map[string]interface{
 	}
 	if spec.Calico != nil {
 		data["name"] = "calico"
+		data["calico"] = flattenCalicoNetworkingSpec(spec.Calico)
 	}
 	if spec.Canal != nil {
 		data["name"] = "canal"
@@ -292,41 +436,226 @@ func flattenNetworkingSpec(spec *kopsapi.NetworkingSpec) []map[string]interface{
 	}
 	if spec.Cilium != nil {
 		data["name"] = "cilium"
+		data["cilium"] = flattenCiliumNetworkingSpec(spec.Cilium)
 	}
 
 	return []map[string]interface{}{data}
 }
 
+func flattenCalicoNetworkingSpec(calico *kopsapi.CalicoNetworkingSpec) []map[string]interface{} {
+	data := make(map[string]interface{})
+	data["cross_subnet"] = calico.CrossSubnet
+	data["log_severity_screen"] = calico.LogSeverityScreen
+	if calico.MTU != nil {
+		data["mtu"] = int(*calico.MTU)
+	}
+	data["prometheus_metrics_enabled"] = calico.PrometheusMetricsEnabled
+	data["prometheus_metrics_port"] = int(calico.PrometheusMetricsPort)
+	data["prometheus_go_metrics_enabled"] = calico.PrometheusGoMetricsEnabled
+	data["prometheus_process_metrics_enabled"] = calico.PrometheusProcessMetricsEnabled
+	return []map[string]interface{}{data}
+}
+
+func flattenCiliumNetworkingSpec(cilium *kopsapi.CiliumNetworkingSpec) []map[string]interface{} {
+	data := make(map[string]interface{})
+	data["version"] = cilium.Version
+	data["agent_labels"] = cilium.AgentLabels
+	data["allow_localhost"] = cilium.AllowLocalhost
+	data["debug"] = cilium.Debug
+	data["device"] = cilium.Device
+	data["disable_conntrack"] = cilium.DisableConntrack
+	data["disable_masquerade"] = cilium.DisableMasquerade
+	data["enable_policy"] = cilium.EnablePolicy
+	data["enable_tracing"] = cilium.EnableTracing
+	data["ipv4_cluster_cidr_mask_size"] = cilium.Ipv4ClusterCIDRMaskSize
+	data["ipv4_range"] = cilium.Ipv4Range
+	data["ipv4_service_range"] = cilium.Ipv4ServiceRange
+	data["lb"] = cilium.LB
+	data["prometheus_serve_addr"] = cilium.PrometheusServeAddr
+	data["single_cluster_route"] = cilium.SingleClusterRoute
+	data["tunnel"] = cilium.Tunnel
+	return []map[string]interface{}{data}
+}
+
 func flattenClusterSubnet(subnets []kopsapi.ClusterSubnetSpec) []map[string]interface{} {
 	var data []map[string]interface{}
 	for _, subnet := range subnets {
 		data = append(data, map[string]interface{}{
-			"name": subnet.Name,
-			"cidr": subnet.CIDR,
-			"zone": subnet.Zone,
-			"type": string(subnet.Type),
+			"name":      subnet.Name,
+			"cidr":      subnet.CIDR,
+			"zone":      subnet.Zone,
+			"type":      string(subnet.Type),
+			"id":        subnet.ProviderID,
+			"egress":    subnet.Egress,
+			"public_ip": subnet.PublicIP,
 		})
 	}
 	return data
 }
 
+func flattenAddonSpec(addons []kopsapi.AddonSpec) []string {
+	var data []string
+	for _, addon := range addons {
+		data = append(data, addon.Manifest)
+	}
+	return data
+}
+
+func flattenAuthenticationSpec(spec *kopsapi.AuthenticationSpec) []map[string]interface{} {
+	data := make(map[string]interface{})
+	if spec.Aws != nil {
+		data["type"] = "aws"
+	}
+	if spec.Kopeio != nil {
+		data["type"] = "kopeio"
+	}
+	return []map[string]interface{}{data}
+}
+
+func flattenAuthorizationSpec(spec *kopsapi.AuthorizationSpec) []map[string]interface{} {
+	data := make(map[string]interface{})
+	if spec.RBAC != nil {
+		data["type"] = "RBAC"
+	}
+	if spec.AlwaysAllow != nil {
+		data["type"] = "AlwaysAllow"
+	}
+	return []map[string]interface{}{data}
+}
+
+func flattenCloudConfiguration(config *kopsapi.CloudConfiguration) []map[string]interface{} {
+	data := make(map[string]interface{})
+	if config.Multizone != nil {
+		data["multizone"] = *config.Multizone
+	}
+	if config.NodeTags != nil {
+		data["node_tags"] = *config.NodeTags
+	}
+	if config.NodeInstancePrefix != nil {
+		data["node_instance_prefix"] = *config.NodeInstancePrefix
+	}
+	if config.DisableSecurityGroupIngress != nil {
+		data["disable_security_group_ingress"] = *config.DisableSecurityGroupIngress
+	}
+	if config.ElbSecurityGroup != nil {
+		data["elb_security_group"] = *config.ElbSecurityGroup
+	}
+	return []map[string]interface{}{data}
+}
+
+func flattenDockerConfig(docker *kopsapi.DockerConfig) []map[string]interface{} {
+	data := make(map[string]interface{})
+	data["authorization_plugins"] = docker.AuthorizationPlugins
+	if docker.Bridge != nil {
+		data["bridge"] = *docker.Bridge
+	}
+	if docker.BridgeIP != nil {
+		data["bridge_ip"] = *docker.BridgeIP
+	}
+	if docker.DataRoot != nil {
+		data["data_root"] = *docker.DataRoot
+	}
+	data["default_ulimit"] = docker.DefaultUlimit
+	if docker.ExecRoot != nil {
+		data["exec_root"] = *docker.ExecRoot
+	}
+	data["hosts"] = docker.Hosts
+	if docker.IPMasq != nil {
+		data["ip_masq"] = *docker.IPMasq
+	}
+	if docker.IPTables != nil {
+		data["ip_tables"] = *docker.IPTables
+	}
+	if docker.InsecureRegistry != nil {
+		data["insecure_registry"] = *docker.InsecureRegistry
+	}
+	if docker.LiveRestore != nil {
+		data["live_restore"] = *docker.LiveRestore
+	}
+	if docker.LogDriver != nil {
+		data["log_driver"] = *docker.LogDriver
+	}
+	if docker.LogLevel != nil {
+		data["log_level"] = *docker.LogLevel
+	}
+	data["log_opt"] = docker.LogOpt
+	if docker.MTU != nil {
+		data["mtu"] = int(*docker.MTU)
+	}
+	data["registry_mirrors"] = docker.RegistryMirrors
+	if docker.Storage != nil {
+		data["storage"] = *docker.Storage
+	}
+	data["storage_opts"] = docker.StorageOpts
+	data["user_namespace_remap"] = docker.UserNamespaceRemap
+	if docker.Version != nil {
+		data["version"] = *docker.Version
+	}
+	return []map[string]interface{}{data}
+}
+
+func flattenAssets(assets *kopsapi.Assets) []map[string]interface{} {
+	data := make(map[string]interface{})
+	if assets.ContainerRegistry != nil {
+		data["container_registry"] = *assets.ContainerRegistry
+	}
+	if assets.ContainerProxy != nil {
+		data["container_proxy"] = *assets.ContainerProxy
+	}
+	if assets.FileRepository != nil {
+		data["file_repository"] = *assets.FileRepository
+	}
+	return []map[string]interface{}{data}
+}
+
+func flattenExternalDNSConfig(config *kopsapi.ExternalDNSConfig) []map[string]interface{} {
+	data := make(map[string]interface{})
+	data["disable"] = config.Disable
+	if config.WatchIngress != nil {
+		data["watch_ingress"] = *config.WatchIngress
+	}
+	data["watch_namespace"] = config.WatchNamespace
+	return []map[string]interface{}{data}
+}
+
+func flattenAPIAccessSpec(api *kopsapi.AccessSpec) []map[string]interface{} {
+	data := make(map[string]interface{})
+	data["dns"] = api.DNS != nil
+	if lb := api.LoadBalancer; lb != nil {
+		balancer := map[string]interface{}{
+			"type":                       string(lb.Type),
+			"additional_security_groups": flattenStringSet(lb.AdditionalSecurityGroups),
+			"use_for_internal_api":       lb.UseForInternalApi,
+			"ssl_certificate":            lb.SSLCertificate,
+		}
+		if lb.IdleTimeoutSeconds != nil {
+			balancer["idle_timeout_seconds"] = int(*lb.IdleTimeoutSeconds)
+		}
+		data["load_balancer"] = []map[string]interface{}{balancer}
+	}
+	return []map[string]interface{}{data}
+}
+
 func flattenClusterTopology(topology *kopsapi.TopologySpec) []map[string]interface{} {
 	data := make(map[string]interface{})
 	data["masters"] = topology.Masters
 	data["nodes"] = topology.Nodes
 	if topology.Bastion != nil {
-		data["bastion"] = []map[string]interface{}{
+		bastion := map[string]interface{}{
+			"bastion_public_name": topology.Bastion.BastionPublicName,
+		}
+		if topology.Bastion.IdleTimeoutSeconds != nil {
+			bastion["idle_timeout_seconds"] = int(*topology.Bastion.IdleTimeoutSeconds)
+		}
+		data["bastion"] = []map[string]interface{}{bastion}
+	}
+	if topology.DNS != nil {
+		data["dns"] = []map[string]interface{}{
 			{
-				"bastion_public_name":  topology.Bastion.BastionPublicName,
-				"idle_timeout_seconds": int(*topology.Bastion.IdleTimeoutSeconds),
+				"type": string(topology.DNS.Type),
 			},
 		}
 	}
-	data["dns"] = []map[string]interface{}{
-		{
-			"type": topology.DNS.Type,
-		},
-	}
 	return []map[string]interface{}{data}
 }
 
@@ -371,10 +700,10 @@ func flattenEtcdClusterSpec(etcdClusters []*kopsapi.EtcdClusterSpec) []map[strin
 		cl["enable_tls_auth"] = cluster.EnableTLSAuth
 		cl["version"] = cluster.Version
 		if cluster.LeaderElectionTimeout != nil {
-			cl["leader_election_timeout"] = cluster.LeaderElectionTimeout
+			cl["leader_election_timeout"] = cluster.LeaderElectionTimeout.Duration.String()
 		}
 		if cluster.HeartbeatInterval != nil {
-			cl["heartbeat_interval"] = cluster.HeartbeatInterval
+			cl["heartbeat_interval"] = cluster.HeartbeatInterval.Duration.String()
 		}
 		cl["image"] = cluster.Image
 		if cluster.Backups != nil {
@@ -404,8 +733,8 @@ func flattenInstanceGroupSpec(ig kopsapi.InstanceGroupSpec) []map[string]interfa
 	data["role"] = ig.Role
 	data["machine_type"] = ig.MachineType
 	data["image"] = ig.Image
-	data["subnets"] = ig.Subnets
-	data["zones"] = ig.Zones
+	data["subnets"] = flattenStringSet(ig.Subnets)
+	data["zones"] = flattenStringSet(ig.Zones)
 	if ig.RootVolumeSize != nil {
 		data["root_volume_size"] = *ig.RootVolumeSize
 	}
@@ -424,20 +753,35 @@ func flattenInstanceGroupSpec(ig kopsapi.InstanceGroupSpec) []map[string]interfa
 	if ig.MaxSize != nil {
 		data["max_size"] = *ig.MaxSize
 	}
+	if ig.MaxPrice != nil {
+		data["max_price"] = *ig.MaxPrice
+	}
 	data["cloud_labels"] = ig.CloudLabels
 	data["node_labels"] = ig.NodeLabels
-	data["additional_security_groups"] = ig.AdditionalSecurityGroups
+	data["taints"] = flattenStringSet(ig.Taints)
+	data["additional_security_groups"] = flattenStringSet(ig.AdditionalSecurityGroups)
 	data["additional_user_data"] = flattenAdditionalUserData(ig.AdditionalUserData)
-	if ig.AssociatePublicIP != nil {
-		data["associate_public_ip"] = *ig.AssociatePublicIP
-	}
+	data["associate_public_ip"] = ig.AssociatePublicIP == nil || *ig.AssociatePublicIP
 	if ig.DetailedInstanceMonitoring != nil {
 		data["detailed_instance_monitoring"] = *ig.DetailedInstanceMonitoring
 	}
 	data["external_load_balancer"] = flattenExternalLoadBalancer(ig.ExternalLoadBalancers)
 	data["file_asset"] = flattenFileAsset(ig.FileAssets)
 	data["hook"] = flattenHook(ig.Hooks)
+	if ig.IAM != nil {
+		data["iam"] = flattenIAMProfileSpec(ig.IAM)
+	}
 	data["kubelet"] = flattenKubeletSpec(ig.Kubelet)
+	data["suspend_processes"] = ig.SuspendProcesses
+	data["tenancy"] = ig.Tenancy
+	return []map[string]interface{}{data}
+}
+
+func flattenIAMProfileSpec(spec *kopsapi.IAMProfileSpec) []map[string]interface{} {
+	data := make(map[string]interface{})
+	if spec.Profile != nil {
+		data["profile"] = *spec.Profile
+	}
 	return []map[string]interface{}{data}
 }
 
@@ -448,19 +792,19 @@ func flattenKubeletSpec(spec *kopsapi.KubeletConfigSpec) []map[string]interface{
 		data["api_servers"] = spec.APIServers
 		data["authorization_mode"] = spec.AuthorizationMode
 		if spec.AllowPrivileged != nil {
-			data["allow_privileged"] = *spec.AllowPrivileged
+			data["allow_privileged"] = strconv.FormatBool(*spec.AllowPrivileged)
 		}
 		if spec.AnonymousAuth != nil {
-			data["anonymous_auth"] = *spec.AnonymousAuth
+			data["anonymous_auth"] = strconv.FormatBool(*spec.AnonymousAuth)
 		}
 		if spec.AuthenticationTokenWebhook != nil {
-			data["authentication_token_webhook"] = *spec.AuthenticationTokenWebhook
+			data["authentication_token_webhook"] = strconv.FormatBool(*spec.AuthenticationTokenWebhook)
 		}
 		if spec.AuthenticationTokenWebhookCacheTTL != nil {
 			data["authentication_token_webhook_cache_ttl"] = spec.AuthenticationTokenWebhookCacheTTL.Duration.String()
 		}
 		if spec.BabysitDaemons != nil {
-			data["babysit_daemons"] = *spec.BabysitDaemons
+			data["babysit_daemons"] = strconv.FormatBool(*spec.BabysitDaemons)
 		}
 		data["bootstrap_kubeconfig"] = spec.BootstrapKubeconfig
 		data["cgroup_root"] = spec.CgroupRoot
@@ -469,16 +813,16 @@ func flattenKubeletSpec(spec *kopsapi.KubeletConfigSpec) []map[string]interface{
 		data["cluster_dns"] = spec.ClusterDNS
 		data["cluster_domain"] = spec.ClusterDomain
 		if spec.ConfigureCBR0 != nil {
-			data["configure_cbr0"] = *spec.ConfigureCBR0
+			data["configure_cbr0"] = strconv.FormatBool(*spec.ConfigureCBR0)
 		}
 		if spec.DockerDisableSharedPID != nil {
-			data["docker_disable_shared_pid"] = *spec.DockerDisableSharedPID
+			data["docker_disable_shared_pid"] = strconv.FormatBool(*spec.DockerDisableSharedPID)
 		}
 		if spec.EnableCustomMetrics != nil {
-			data["enable_custom_metrics"] = *spec.EnableCustomMetrics
+			data["enable_custom_metrics"] = strconv.FormatBool(*spec.EnableCustomMetrics)
 		}
 		if spec.EnableDebuggingHandlers != nil {
-			data["enable_debugging_handlers"] = *spec.EnableDebuggingHandlers
+			data["enable_debugging_handlers"] = strconv.FormatBool(*spec.EnableDebuggingHandlers)
 		}
 		data["enforce_node_allocatable"] = spec.EnforceNodeAllocatable
 		if spec.EvictionHard != nil {
@@ -494,17 +838,17 @@ func flattenKubeletSpec(spec *kopsapi.KubeletConfigSpec) []map[string]interface{
 		data["eviction_soft_grace_period"] = spec.EvictionSoftGracePeriod
 		data["experimental_allowed_unsafe_sysctls"] = spec.ExperimentalAllowedUnsafeSysctls
 		if spec.FailSwapOn != nil {
-			data["fail_swap_on"] = *spec.FailSwapOn
+			data["fail_swap_on"] = strconv.FormatBool(*spec.FailSwapOn)
 		}
 
 		data["feature_gates"] = spec.FeatureGates
 		data["hairpin_mode"] = spec.HairpinMode
 		data["hostname_override"] = spec.HostnameOverride
 		if spec.ImageGCHighThresholdPercent != nil {
-			data["image_gc_high_threshold_percent"] = int(*spec.ImageGCHighThresholdPercent)
+			data["image_gc_high_threshold_percent"] = strconv.Itoa(int(*spec.ImageGCHighThresholdPercent))
 		}
 		if spec.ImageGCLowThresholdPercent != nil {
-			data["image_gc_low_threshold_percent"] = int(*spec.ImageGCLowThresholdPercent)
+			data["image_gc_low_threshold_percent"] = strconv.Itoa(int(*spec.ImageGCLowThresholdPercent))
 		}
 		if spec.ImagePullProgressDeadline != nil {
 			data["image_pull_progress_deadline"] = spec.ImagePullProgressDeadline.Duration.String()
@@ -514,13 +858,13 @@ func flattenKubeletSpec(spec *kopsapi.KubeletConfigSpec) []map[string]interface{
 		data["kube_reserved"] = spec.KubeReserved
 		data["kube_reserved_cgroup"] = spec.KubeReservedCgroup
 		if spec.LogLevel != nil {
-			data["log_level"] = int(*spec.LogLevel)
+			data["log_level"] = strconv.Itoa(int(*spec.LogLevel))
 		}
 		if spec.MaxPods != nil {
-			data["max_pods"] = int(*spec.MaxPods)
+			data["max_pods"] = strconv.Itoa(int(*spec.MaxPods))
 		}
 		if spec.NetworkPluginMTU != nil {
-			data["network_plugin_mtu"] = int(*spec.NetworkPluginMTU)
+			data["network_plugin_mtu"] = strconv.Itoa(int(*spec.NetworkPluginMTU))
 		}
 
 		data["network_plugin_name"] = spec.NetworkPluginName
@@ -536,19 +880,19 @@ func flattenKubeletSpec(spec *kopsapi.KubeletConfigSpec) []map[string]interface{
 		data["pod_infra_container_image"] = spec.PodInfraContainerImage
 		data["pod_manifest_path"] = spec.PodManifestPath
 		if spec.ReadOnlyPort != nil {
-			data["read_only_port"] = int(*spec.ReadOnlyPort)
+			data["read_only_port"] = strconv.Itoa(int(*spec.ReadOnlyPort))
 		}
 		if spec.ReconcileCIDR != nil {
-			data["reconcile_cidr"] = *spec.ReconcileCIDR
+			data["reconcile_cidr"] = strconv.FormatBool(*spec.ReconcileCIDR)
 		}
 		if spec.RegisterNode != nil {
-			data["register_node"] = *spec.RegisterNode
+			data["register_node"] = strconv.FormatBool(*spec.RegisterNode)
 		}
 		if spec.RegisterSchedulable != nil {
-			data["register_schedulable"] = *spec.RegisterSchedulable
+			data["register_schedulable"] = strconv.FormatBool(*spec.RegisterSchedulable)
 		}
 		if spec.RequireKubeconfig != nil {
-			data["require_kubeconfig"] = *spec.RequireKubeconfig
+			data["require_kubeconfig"] = strconv.FormatBool(*spec.RequireKubeconfig)
 		}
 		if spec.ResolverConfig != nil {
 			data["resolver_config"] = *spec.ResolverConfig
@@ -562,7 +906,7 @@ func flattenKubeletSpec(spec *kopsapi.KubeletConfigSpec) []map[string]interface{
 			data["seccomp_profile_root"] = *spec.SeccompProfileRoot
 		}
 		if spec.SerializeImagePulls != nil {
-			data["serialize_image_pulls"] = *spec.SerializeImagePulls
+			data["serialize_image_pulls"] = strconv.FormatBool(*spec.SerializeImagePulls)
 		}
 		if spec.StreamingConnectionIdleTimeout != nil {
 			data["streaming_connection_idle_timeout"] = spec.StreamingConnectionIdleTimeout.Duration.String()
@@ -570,7 +914,7 @@ func flattenKubeletSpec(spec *kopsapi.KubeletConfigSpec) []map[string]interface{
 		data["system_cgroups"] = spec.SystemCgroups
 		data["system_reserved"] = spec.SystemReserved
 		data["system_reserved_cgroup"] = spec.SystemReservedCgroup
-		data["taints"] = spec.Taints
+		data["taints"] = flattenStringSet(spec.Taints)
 		data["tls_cert_file"] = spec.TLSCertFile
 		data["tls_private_key_file"] = spec.TLSPrivateKeyFile
 		data["volume_plugin_directory"] = spec.VolumePluginDirectory
@@ -583,49 +927,53 @@ func flattenKubeletSpec(spec *kopsapi.KubeletConfigSpec) []map[string]interface{
 }
 
 func flattenHook(specs []kopsapi.HookSpec) []map[string]interface{} {
-	data := make(map[string]interface{})
+	var data []map[string]interface{}
 
 	for _, hook := range specs {
-		data["name"] = hook.Name
-		data["disabled"] = hook.Disabled
-		data["manifest"] = hook.Manifest
-		data["before"] = hook.Before
-		data["requires"] = hook.Requires
-
 		roles := make([]string, len(hook.Roles))
 		for i, role := range hook.Roles {
 			roles[i] = string(role)
 		}
 
-		data["roles"] = roles
-		data["exec_container"] = flattenExecContainerSpec(hook.ExecContainer)
+		data = append(data, map[string]interface{}{
+			"name":           hook.Name,
+			"disabled":       hook.Disabled,
+			"manifest":       hook.Manifest,
+			"before":         hook.Before,
+			"requires":       hook.Requires,
+			"roles":          roles,
+			"exec_container": flattenExecContainerSpec(hook.ExecContainer),
+		})
 	}
 
-	return []map[string]interface{}{data}
+	return data
 }
 
-func flattenExecContainerSpec(action *kopsapi.ExecContainerAction) interface{} {
-	data := make(map[string]interface{})
-
-	if action != nil {
-		data["image"] = action.Image
-		data["command"] = action.Command
-		data["environment"] = action.Environment
+func flattenExecContainerSpec(action *kopsapi.ExecContainerAction) []map[string]interface{} {
+	if action == nil {
+		return nil
 	}
 
+	data := make(map[string]interface{})
+	data["image"] = action.Image
+	data["command"] = action.Command
+	data["environment"] = action.Environment
+
 	return []map[string]interface{}{data}
 }
 
 func flattenAdditionalUserData(ud []kopsapi.UserData) []map[string]interface{} {
-	data := make(map[string]interface{})
+	var data []map[string]interface{}
 
 	for _, userData := range ud {
-		data["name"] = userData.Name
-		data["type"] = userData.Type
-		data["content"] = userData.Content
+		data = append(data, map[string]interface{}{
+			"name":    userData.Name,
+			"type":    userData.Type,
+			"content": userData.Content,
+		})
 	}
 
-	return []map[string]interface{}{data}
+	return data
 }
 
 func flattenExternalLoadBalancer(bl []kopsapi.LoadBalancer) []map[string]interface{} {
@@ -640,15 +988,31 @@ func flattenExternalLoadBalancer(bl []kopsapi.LoadBalancer) []map[string]interfa
 }
 
 func flattenFileAsset(specs []kopsapi.FileAssetSpec) []map[string]interface{} {
-	data := make(map[string]interface{})
+	var data []map[string]interface{}
 
 	for _, fa := range specs {
-		data["name"] = fa.Name
-		data["path"] = fa.Path
-		data["content"] = fa.Content
-		data["is_base64"] = fa.IsBase64
-		data["roles"] = fa.Roles
+		roles := make([]string, len(fa.Roles))
+		for i, role := range fa.Roles {
+			roles[i] = string(role)
+		}
+
+		data = append(data, map[string]interface{}{
+			"name":      fa.Name,
+			"path":      fa.Path,
+			"content":   fa.Content,
+			"is_base64": fa.IsBase64,
+			"roles":     roles,
+		})
 	}
 
-	return []map[string]interface{}{data}
+	return data
+}
+
+// flattenStringSet builds the sets of string lists, Terraform can't convert slices to sets nested in lists
+func flattenStringSet(values []string) *schema.Set {
+	items := make([]interface{}, len(values))
+	for i, value := range values {
+		items[i] = value
+	}
+	return schema.NewSet(schema.HashString, items)
 }
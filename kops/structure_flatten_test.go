@@ -0,0 +1,31 @@
+package kops
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	kopsapi "k8s.io/kops/pkg/apis/kops"
+)
+
+func TestFlattenInstanceGroupSpecSets(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, map[string]*schema.Schema{"spec": schemaInstanceGroupSpec()}, map[string]interface{}{})
+
+	spec := kopsapi.InstanceGroupSpec{
+		Role:                     kopsapi.InstanceGroupRoleNode,
+		Subnets:                  []string{"eu-west-1b", "eu-west-1a"},
+		Taints:                   []string{"dedicated=gpu:NoSchedule"},
+		AdditionalSecurityGroups: []string{"sg-12345678"},
+		Kubelet:                  &kopsapi.KubeletConfigSpec{Taints: []string{"spot=true:NoSchedule"}},
+	}
+	if err := d.Set("spec", flattenInstanceGroupSpec(spec)); err != nil {
+		t.Fatal(err)
+	}
+
+	expanded := expandInstanceGroupSpec(d.Get("spec").([]interface{})[0].(map[string]interface{}))
+	if len(expanded.Subnets) != 2 || len(expanded.Taints) != 1 || len(expanded.AdditionalSecurityGroups) != 1 {
+		t.Errorf("expected the sets to read back, got subnets %v, taints %v, security groups %v", expanded.Subnets, expanded.Taints, expanded.AdditionalSecurityGroups)
+	}
+	if expanded.Kubelet == nil || len(expanded.Kubelet.Taints) != 1 {
+		t.Errorf("expected the kubelet taints to read back, got %+v", expanded.Kubelet)
+	}
+}
@@ -0,0 +1,48 @@
+package kops
+
+import (
+	"time"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+const defaultOperationTimeout = 20 * time.Minute
+
+// resourceTimeouts declares the operation timeouts of the resources, they are zero unless the resource configures them
+func resourceTimeouts() *schema.ResourceTimeout {
+	return &schema.ResourceTimeout{
+		Create: new(time.Duration),
+		Update: new(time.Duration),
+		Delete: new(time.Duration),
+	}
+}
+
+func expandProviderTimeouts(data []interface{}) map[string]time.Duration {
+	timeouts := make(map[string]time.Duration)
+	if len(data) > 0 {
+		conv := data[0].(map[string]interface{})
+		for _, key := range []string{schema.TimeoutCreate, schema.TimeoutUpdate, schema.TimeoutDelete} {
+			if timeout, err := time.ParseDuration(conv[key].(string)); err == nil {
+				timeouts[key] = timeout
+			}
+		}
+	}
+	return timeouts
+}
+
+// operationTimeout returns the timeout of a resource operation, the provider default applies unless the resource overrides it
+func operationTimeout(d *schema.ResourceData, m interface{}, key string) time.Duration {
+	timeout := d.Timeout(key)
+	if timeout > 0 {
+		return timeout
+	}
+	if providerTimeout, ok := m.(*ProviderConfig).timeouts[key]; ok {
+		return providerTimeout
+	}
+	return defaultOperationTimeout
+}
+
+// operationDeadline returns the time a resource operation started now has to finish by
+func operationDeadline(d *schema.ResourceData, m interface{}, key string) time.Time {
+	return time.Now().Add(operationTimeout(d, m, key))
+}
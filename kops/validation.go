@@ -0,0 +1,275 @@
+package kops
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/hashicorp/terraform/helper/schema"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/apis/kops/validation"
+	"k8s.io/kops/upup/pkg/fi/cloudup/awsup"
+)
+
+// arm64MachineFamily matches the AWS Graviton instance families, a1 and the families with a g after the generation (m6g, c6gn, t4g, ...)
+var arm64MachineFamily = regexp.MustCompile(`^(a1|[a-z]+[0-9]+g[a-z]*)$`)
+
+// validateClusterSpec runs the kops cluster validation at plan time, the same validation the state store runs on create and update.
+func validateClusterSpec(d *schema.ResourceDiff, m interface{}) error {
+	// values interpolated from resources not created yet are empty at plan time, they are validated on apply
+	if !newValuesKnown(d, "metadata.0.name", "spec.0.kubernetes_version", "spec.0.cloud_provider",
+		"spec.0.network_cidr", "spec.0.non_masquerade_cidr", "spec.0.subnet") {
+		return nil
+	}
+
+	metadata, spec := diffSectionData(d, "metadata"), diffSectionData(d, "spec")
+	if metadata == nil || spec == nil {
+		return nil
+	}
+
+	cluster := &kops.Cluster{
+		ObjectMeta: expandObjectMeta(metadata),
+		Spec:       expandClusterSpec(spec),
+	}
+	applyProviderDefaults(cluster, m)
+
+	if err := validateEtcdClusters(cluster); err != nil {
+		return err
+	}
+	if err := validation.ValidateCluster(cluster, false); err != nil {
+		return err
+	}
+	return nil
+}
+
+// validateEtcdClusters checks every etcd cluster has an odd number of members, an even number adds a member
+// without tolerating one more failure, and kops rejects it only after the checks of the other fields
+func validateEtcdClusters(cluster *kops.Cluster) error {
+	for _, etcd := range cluster.Spec.EtcdClusters {
+		if len(etcd.Members)%2 == 0 {
+			return fmt.Errorf("etcd cluster %q of cluster %s has %d members, etcd needs an odd number of members for quorum", etcd.Name, cluster.Name, len(etcd.Members))
+		}
+	}
+	return nil
+}
+
+// validateInstanceGroupSize checks the size bounds of an instance group as soon as they are known,
+// the kops instance group validation waits for the whole spec
+func validateInstanceGroupSize(d *schema.ResourceDiff, m interface{}) error {
+	if !newValuesKnown(d, "spec.0.min_size", "spec.0.max_size") {
+		return nil
+	}
+	minSize, maxSize := d.Get("spec.0.min_size").(int), d.Get("spec.0.max_size").(int)
+	if minSize > maxSize {
+		return fmt.Errorf("min_size %d of instance group %s is greater than its max_size %d", minSize, d.Get("metadata.0.name"), maxSize)
+	}
+	return nil
+}
+
+// validateInstanceGroupSpec runs the kops instance group validation at plan time.
+func validateInstanceGroupSpec(d *schema.ResourceDiff, m interface{}) error {
+	if !newValuesKnown(d, "metadata.0.name", "spec.0.role", "spec.0.min_size", "spec.0.max_size", "spec.0.subnets", "spec.0.zones") {
+		return nil
+	}
+
+	metadata, spec := diffSectionData(d, "metadata"), diffSectionData(d, "spec")
+	if metadata == nil || spec == nil {
+		return nil
+	}
+
+	instanceGroup := &kops.InstanceGroup{
+		ObjectMeta: expandObjectMeta(metadata),
+		Spec:       expandInstanceGroupSpec(spec),
+	}
+	applyInstanceGroupDefaults(instanceGroup, m)
+
+	cluster, err := diffCluster(d, m)
+	if err != nil {
+		return err
+	}
+	if cluster == nil {
+		return validation.ValidateInstanceGroup(instanceGroup)
+	}
+
+	if err := validation.CrossValidateInstanceGroup(instanceGroup, cluster, false); err != nil {
+		return err
+	}
+	if err := validateInstanceGroupZones(instanceGroup, cluster); err != nil {
+		return err
+	}
+	switch instanceGroup.Spec.Role {
+	case kops.InstanceGroupRoleMaster:
+		return validateMasterInstanceGroup(instanceGroup, cluster)
+	case kops.InstanceGroupRoleBastion:
+		return validateBastionInstanceGroup(instanceGroup, cluster)
+	}
+	return nil
+}
+
+// validateInstanceGroupZones checks the zones of an instance group are zones of the cluster subnets, kops only checks the subnets
+func validateInstanceGroupZones(instanceGroup *kops.InstanceGroup, cluster *kops.Cluster) error {
+	zones := make(map[string]bool)
+	for _, subnet := range cluster.Spec.Subnets {
+		zones[subnet.Zone] = true
+	}
+	for _, zone := range instanceGroup.Spec.Zones {
+		if !zones[zone] {
+			return fmt.Errorf("instance group %s is configured in zone %q, but no subnet of cluster %s is in this zone", instanceGroup.Name, zone, cluster.Name)
+		}
+	}
+	return nil
+}
+
+// validateMasterInstanceGroup checks every etcd cluster has a member running on a master instance group,
+// masters of groups without a member never join etcd
+func validateMasterInstanceGroup(instanceGroup *kops.InstanceGroup, cluster *kops.Cluster) error {
+	for _, etcd := range cluster.Spec.EtcdClusters {
+		found := false
+		for _, member := range etcd.Members {
+			if member.InstanceGroup != nil && *member.InstanceGroup == instanceGroup.Name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("master instance group %s is not the instance_group of any member of etcd cluster %q of cluster %s", instanceGroup.Name, etcd.Name, cluster.Name)
+		}
+	}
+	return nil
+}
+
+// validateBastionInstanceGroup checks the cluster topology can host bastions, kops puts their load balancer
+// in the utility subnets of the private topology
+func validateBastionInstanceGroup(instanceGroup *kops.InstanceGroup, cluster *kops.Cluster) error {
+	topology := cluster.Spec.Topology
+	if topology == nil || topology.Masters != kops.TopologyPrivate || topology.Nodes != kops.TopologyPrivate {
+		return fmt.Errorf("bastion instance group %s needs the private topology, cluster %s doesn't use it", instanceGroup.Name, cluster.Name)
+	}
+
+	utilityZones := make(map[string]bool)
+	subnetZones := make(map[string]string)
+	for _, subnet := range cluster.Spec.Subnets {
+		if subnet.Type == kops.SubnetTypeUtility {
+			utilityZones[subnet.Zone] = true
+		}
+		subnetZones[subnet.Name] = subnet.Zone
+	}
+	for _, subnet := range instanceGroup.Spec.Subnets {
+		if zone := subnetZones[subnet]; !utilityZones[zone] {
+			return fmt.Errorf("bastion instance group %s is configured in subnet %q, but cluster %s has no utility subnet in zone %q", instanceGroup.Name, subnet, cluster.Name, zone)
+		}
+	}
+	return nil
+}
+
+// validateInstanceGroupImage resolves the image of AWS instance groups at plan time and checks its architecture
+// matches the machine type, instead of failing when the instances are launched.
+// It is skipped when the cluster is not in the state store yet.
+func validateInstanceGroupImage(d *schema.ResourceDiff, m interface{}) error {
+	if !d.HasChange("spec.0.image") && !d.HasChange("spec.0.machine_type") {
+		return nil
+	}
+	if !newValuesKnown(d, "spec.0.image", "spec.0.machine_type") {
+		return nil
+	}
+
+	name := d.Get("spec.0.image").(string)
+	if name == "" {
+		return nil
+	}
+
+	cloud, err := diffAWSCloud(d, m)
+	if err != nil || cloud == nil {
+		return err
+	}
+
+	image, err := cloud.ResolveImage(name)
+	if err != nil {
+		return fmt.Errorf("image %q of instance group %s: %v", name, d.Get("metadata.0.name"), err)
+	}
+
+	machineType := d.Get("spec.0.machine_type").(string)
+	if machineType == "" {
+		return nil
+	}
+	family := strings.SplitN(machineType, ".", 2)[0]
+	architecture := aws.StringValue(image.Architecture)
+	if arm64MachineFamily.MatchString(family) != (architecture == ec2.ArchitectureValuesArm64) {
+		return fmt.Errorf("image %q of instance group %s is built for %s, which machine type %s can't run", name, d.Get("metadata.0.name"), architecture, machineType)
+	}
+	return nil
+}
+
+// diffAWSCloud returns the AWS cloud of the cluster an instance group belongs to, nil when the cluster isn't on AWS or isn't known yet
+func diffAWSCloud(d *schema.ResourceDiff, m interface{}) (awsup.AWSCloud, error) {
+	cluster, err := diffCluster(d, m)
+	if err != nil || cluster == nil {
+		return nil, err
+	}
+	return clusterAWSCloud(cluster, m)
+}
+
+// clusterAWSCloud returns the AWS cloud of a cluster, nil when the cluster isn't on AWS
+func clusterAWSCloud(cluster *kops.Cluster, m interface{}) (awsup.AWSCloud, error) {
+	if kops.CloudProviderID(cluster.Spec.CloudProvider) != kops.CloudProviderAWS {
+		return nil, nil
+	}
+
+	if err := configureAWSCloud(cluster, m); err != nil {
+		return nil, err
+	}
+	region, err := awsup.FindRegion(cluster)
+	if err != nil {
+		return nil, err
+	}
+	cloud, err := awsup.NewAWSCloud(region, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error initializing AWS cloud for region %q: %v", region, err)
+	}
+	return cloud, nil
+}
+
+// diffCluster reads the cluster an instance group belongs to from the state store, nil when it isn't known or doesn't exist yet
+func diffCluster(d *schema.ResourceDiff, m interface{}) (*kops.Cluster, error) {
+	if !newValuesKnown(d, "state_store", "cluster_name") {
+		return nil, nil
+	}
+
+	config := m.(*ProviderConfig)
+	clusterName := d.Get("cluster_name").(string)
+	if clusterName == "" {
+		clusterName = config.defaultClusterName
+	}
+	if clusterName == "" {
+		return nil, nil
+	}
+
+	clientset, err := config.clientsetFor(d.Get("state_store").(string))
+	if err != nil {
+		return nil, err
+	}
+	cluster, err := clientset.GetCluster(clusterName)
+	if errors.IsNotFound(err) {
+		return nil, nil
+	}
+	return cluster, err
+}
+
+func newValuesKnown(d *schema.ResourceDiff, keys ...string) bool {
+	for _, key := range keys {
+		if !d.NewValueKnown(key) {
+			return false
+		}
+	}
+	return true
+}
+
+func diffSectionData(d *schema.ResourceDiff, section string) map[string]interface{} {
+	if data, ok := d.Get(section).([]interface{}); ok && len(data) > 0 && data[0] != nil {
+		return data[0].(map[string]interface{})
+	}
+	return nil
+}
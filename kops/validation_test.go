@@ -0,0 +1,64 @@
+package kops
+
+import (
+	"strings"
+	"testing"
+
+	"k8s.io/kops/pkg/apis/kops"
+)
+
+func etcdCluster(name string, members ...string) *kops.EtcdClusterSpec {
+	etcd := &kops.EtcdClusterSpec{Name: name}
+	for _, member := range members {
+		instanceGroup := "master-" + member
+		etcd.Members = append(etcd.Members, &kops.EtcdMemberSpec{Name: member, InstanceGroup: &instanceGroup})
+	}
+	return etcd
+}
+
+func TestValidateEtcdClusters(t *testing.T) {
+	tests := []struct {
+		name    string
+		etcd    []*kops.EtcdClusterSpec
+		invalid bool
+	}{
+		{"single member", []*kops.EtcdClusterSpec{etcdCluster("main", "a")}, false},
+		{"three members", []*kops.EtcdClusterSpec{etcdCluster("main", "a", "b", "c"), etcdCluster("events", "a", "b", "c")}, false},
+		{"two members", []*kops.EtcdClusterSpec{etcdCluster("main", "a", "b")}, true},
+		{"four members in events", []*kops.EtcdClusterSpec{etcdCluster("main", "a", "b", "c"), etcdCluster("events", "a", "b", "c", "d")}, true},
+		{"no members", []*kops.EtcdClusterSpec{etcdCluster("main")}, true},
+	}
+	for _, test := range tests {
+		cluster := &kops.Cluster{Spec: kops.ClusterSpec{EtcdClusters: test.etcd}}
+		cluster.Name = "test.example.com"
+		if err := validateEtcdClusters(cluster); (err != nil) != test.invalid {
+			t.Errorf("%s: expected invalid %v, got %v", test.name, test.invalid, err)
+		}
+	}
+}
+
+func TestArm64MachineFamily(t *testing.T) {
+	tests := []struct {
+		machineType string
+		arm64       bool
+	}{
+		{"a1.large", true},
+		{"t4g.micro", true},
+		{"m6g.xlarge", true},
+		{"c6gn.2xlarge", true},
+		{"c7gd.medium", true},
+		{"g5g.xlarge", true},
+		{"im4gn.large", true},
+		{"g4dn.xlarge", false},
+		{"m7i-flex.large", false},
+		{"m5.large", false},
+		{"t3a.nano", false},
+		{"p4d.24xlarge", false},
+	}
+	for _, test := range tests {
+		family := strings.SplitN(test.machineType, ".", 2)[0]
+		if arm64 := arm64MachineFamily.MatchString(family); arm64 != test.arm64 {
+			t.Errorf("%s: expected arm64 %v, got %v", test.machineType, test.arm64, arm64)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package kops
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/blang/semver"
+	"github.com/hashicorp/terraform/helper/schema"
+	kopsbase "k8s.io/kops"
+	"k8s.io/kops/pkg/apis/kops"
+	"k8s.io/kops/pkg/apis/kops/util"
+	"k8s.io/kops/pkg/assets"
+	"k8s.io/kops/pkg/client/simple"
+	"k8s.io/kops/upup/pkg/fi/cloudup"
+)
+
+// checkKopsVersion ensures the vendored kops version satisfies the semver range required by the configuration
+func checkKopsVersion(required string) error {
+	if required == "" {
+		return nil
+	}
+
+	versionRange, err := semver.ParseRange(required)
+	if err != nil {
+		return fmt.Errorf("invalid required_kops_channel_version %q: %v", required, err)
+	}
+
+	version, err := semver.ParseTolerant(kopsbase.Version)
+	if err != nil {
+		return fmt.Errorf("error parsing kops version %q: %v", kopsbase.Version, err)
+	}
+
+	if !versionRange(version) {
+		return fmt.Errorf("kops version %s of the provider does not satisfy required_kops_channel_version %q", kopsbase.Version, required)
+	}
+	return nil
+}
+
+// validateKubernetesVersion rejects clusters using a Kubernetes minor version newer than the vendored kops supports,
+// kops only populates correct defaults for Kubernetes versions up to its own minor version.
+func validateKubernetesVersion(d *schema.ResourceDiff, m interface{}) error {
+	if m.(*ProviderConfig).requiredKopsVersion == "" {
+		return nil
+	}
+
+	kubernetesVersion, ok := d.Get("spec.0.kubernetes_version").(string)
+	if !ok || kubernetesVersion == "" {
+		return nil
+	}
+
+	k8sVersion, err := util.ParseKubernetesVersion(kubernetesVersion)
+	if err != nil {
+		return fmt.Errorf("invalid kubernetes_version %q: %v", kubernetesVersion, err)
+	}
+
+	kopsVersion, err := semver.ParseTolerant(kopsbase.Version)
+	if err != nil {
+		return fmt.Errorf("error parsing kops version %q: %v", kopsbase.Version, err)
+	}
+
+	if k8sVersion.Major > kopsVersion.Major || (k8sVersion.Major == kopsVersion.Major && k8sVersion.Minor > kopsVersion.Minor) {
+		return fmt.Errorf("kubernetes_version %s is not supported by kops %s of the provider, use Kubernetes %d.%d or older", kubernetesVersion, kopsbase.Version, kopsVersion.Major, kopsVersion.Minor)
+	}
+	return nil
+}
+
+// upgradeCluster populates the spec of a cluster moving to another Kubernetes version. The state holds the spec
+// populated for the previous version, the component images tagged with it are cleared so kops resolves them again.
+func upgradeCluster(clientset simple.Clientset, cluster *kops.Cluster, previousVersion string) (*kops.Cluster, error) {
+	clearVersionedImages(&cluster.Spec, previousVersion)
+
+	fullCluster, err := cloudup.PopulateClusterSpec(clientset, cluster, assets.NewAssetBuilder(cluster, ""))
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("[WARN] Cluster %q upgraded from Kubernetes %s to %s, the instances are replaced by a rolling update only: kops rolling-update cluster %s --yes",
+		cluster.Name, previousVersion, cluster.Spec.KubernetesVersion, cluster.Name)
+	return fullCluster, nil
+}
+
+func clearVersionedImages(spec *kops.ClusterSpec, version string) {
+	tag := ":v" + strings.TrimPrefix(version, "v")
+	clear := func(image *string) {
+		if strings.HasSuffix(*image, tag) {
+			*image = ""
+		}
+	}
+
+	if spec.KubeAPIServer != nil {
+		clear(&spec.KubeAPIServer.Image)
+	}
+	if spec.KubeControllerManager != nil {
+		clear(&spec.KubeControllerManager.Image)
+	}
+	if spec.KubeScheduler != nil {
+		clear(&spec.KubeScheduler.Image)
+	}
+	if spec.KubeProxy != nil {
+		clear(&spec.KubeProxy.Image)
+	}
+}
@@ -6,5 +6,6 @@ import (
 )
 
 func main() {
+	defer kops.Cleanup()
 	plugin.Serve(&plugin.ServeOpts{ProviderFunc: kops.Provider})
 }